@@ -0,0 +1,217 @@
+package bandwidth
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// credential is a username and password accepted by CallbackAuth until expires, if set
+type credential struct {
+	username string
+	password string
+	expires  time.Time
+}
+
+type authOptions struct {
+	credentials []credential
+	sources     []string
+	proxies     int
+	maxBodySize int64
+}
+
+// AuthOption configures CallbackAuth
+type AuthOption func(*authOptions)
+
+// WithCallbackCredentials accepts callbacks sent with the given basic auth credentials i.e. the
+// Username and Password of CreateCallInput, UpdateCallInput, or a BXML verb
+func WithCallbackCredentials(username, password string) AuthOption {
+	return func(o *authOptions) {
+		o.credentials = append(o.credentials, credential{username: username, password: password})
+	}
+}
+
+// WithFallbackCredentials accepts callbacks sent to the fallback url with the given basic auth
+// credentials i.e. FallbackUsername and FallbackPassword
+func WithFallbackCredentials(username, password string) AuthOption {
+	return WithCallbackCredentials(username, password)
+}
+
+// WithRotatedCredentials accepts the given credentials until expires.  Use while rotating
+// credentials so calls created with the old credentials continue to work.
+func WithRotatedCredentials(username, password string, expires time.Time) AuthOption {
+	return func(o *authOptions) {
+		o.credentials = append(o.credentials, credential{username: username, password: password, expires: expires})
+	}
+}
+
+// WithAllowedSources only accepts callbacks from the given addresses or CIDR blocks
+// e.g. 192.0.2.0/24
+func WithAllowedSources(sources ...string) AuthOption {
+	return func(o *authOptions) {
+		o.sources = append(o.sources, sources...)
+	}
+}
+
+// WithForwardedFor uses the X-Forwarded-For header as the source of the callback when the server
+// sits behind the given number of trusted proxies.  The address appended by the outermost trusted
+// proxy, counting from the right, is used; addresses to its left are set by the client and are
+// ignored.  Requests with fewer addresses than proxies are rejected.
+func WithForwardedFor(proxies int) AuthOption {
+	return func(o *authOptions) {
+		o.proxies = proxies
+	}
+}
+
+// WithMaxBodySize rejects callbacks larger than n bytes; defaults to 1MB
+func WithMaxBodySize(n int64) AuthOption {
+	return func(o *authOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// CallbackAuth verifies callbacks before passing them to the wrapped handler.  Callbacks must
+// carry one of the configured basic auth credentials, come from an allowed source, and be no
+// larger than the max body size.  If no credentials are configured, any credentials are accepted.
+//
+//	auth, err := bandwidth.NewCallbackAuth(
+//		bandwidth.WithCallbackCredentials("user", "new-password"),
+//		bandwidth.WithRotatedCredentials("user", "old-password", deadline),
+//	)
+//	http.Handle("/callbacks", auth.Wrap(router))
+type CallbackAuth struct {
+	options  authOptions
+	networks []*net.IPNet
+	now      func() time.Time
+}
+
+// NewCallbackAuth returns a new CallbackAuth
+func NewCallbackAuth(opts ...AuthOption) (*CallbackAuth, error) {
+	options := authOptions{
+		maxBodySize: maxCallbackSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var networks []*net.IPNet
+	for _, source := range options.sources {
+		if !strings.Contains(source, "/") {
+			if ip := net.ParseIP(source); ip != nil && ip.To4() != nil {
+				source += "/32"
+			} else {
+				source += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(source)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse allowed source, %v: %w", source, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return &CallbackAuth{
+		options:  options,
+		networks: networks,
+		now:      time.Now,
+	}, nil
+}
+
+// Wrap returns a handler that verifies each callback before calling next
+func (a *CallbackAuth) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !a.allowed(req) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if !a.authorized(req) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="bandwidth"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		if req.ContentLength > a.options.maxBodySize {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(req.Body, a.options.maxBodySize+1))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to read callback: %v", err), http.StatusBadRequest)
+			return
+		}
+		if int64(len(data)) > a.options.maxBodySize {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// authorized returns true if the request carries unexpired credentials
+func (a *CallbackAuth) authorized(req *http.Request) bool {
+	if len(a.options.credentials) == 0 {
+		return true
+	}
+
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	now := a.now()
+	var found bool
+	for _, c := range a.options.credentials {
+		if !c.expires.IsZero() && now.After(c.expires) {
+			continue
+		}
+		// compare every credential to avoid leaking which matched through timing
+		u := subtle.ConstantTimeCompare([]byte(username), []byte(c.username))
+		p := subtle.ConstantTimeCompare([]byte(password), []byte(c.password))
+		if u&p == 1 {
+			found = true
+		}
+	}
+	return found
+}
+
+// allowed returns true if the request comes from an allowed source
+func (a *CallbackAuth) allowed(req *http.Request) bool {
+	if len(a.networks) == 0 {
+		return true
+	}
+
+	addr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if a.options.proxies > 0 {
+		var forwarded []string
+		for _, v := range req.Header.Values("X-Forwarded-For") {
+			forwarded = append(forwarded, strings.Split(v, ",")...)
+		}
+		if len(forwarded) < a.options.proxies {
+			return false
+		}
+		addr = strings.TrimSpace(forwarded[len(forwarded)-a.options.proxies])
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
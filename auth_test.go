@@ -0,0 +1,167 @@
+package bandwidth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallbackAuth(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	auth, err := NewCallbackAuth(
+		WithCallbackCredentials("user", "new"),
+		WithFallbackCredentials("fallback", "secret"),
+		WithRotatedCredentials("user", "old", now.Add(time.Hour)),
+		WithAllowedSources("192.0.2.0/24", "198.51.100.7"),
+		WithMaxBodySize(32),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	auth.now = func() time.Time { return now }
+
+	h := auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	testCases := map[string]struct {
+		Username   string
+		Password   string
+		RemoteAddr string
+		Body       string
+		After      time.Duration
+		Want       int
+	}{
+		"ok": {
+			Username: "user",
+			Password: "new",
+			Want:     http.StatusNoContent,
+		},
+		"fallback": {
+			Username: "fallback",
+			Password: "secret",
+			Want:     http.StatusNoContent,
+		},
+		"rotated": {
+			Username: "user",
+			Password: "old",
+			Want:     http.StatusNoContent,
+		},
+		"rotated expired": {
+			Username: "user",
+			Password: "old",
+			After:    2 * time.Hour,
+			Want:     http.StatusUnauthorized,
+		},
+		"bad password": {
+			Username: "user",
+			Password: "nope",
+			Want:     http.StatusUnauthorized,
+		},
+		"no credentials": {
+			Want: http.StatusUnauthorized,
+		},
+		"single address": {
+			Username:   "user",
+			Password:   "new",
+			RemoteAddr: "198.51.100.7:443",
+			Want:       http.StatusNoContent,
+		},
+		"source": {
+			Username:   "user",
+			Password:   "new",
+			RemoteAddr: "203.0.113.1:443",
+			Want:       http.StatusForbidden,
+		},
+		"too large": {
+			Username: "user",
+			Password: "new",
+			Body:     strings.Repeat("x", 33),
+			Want:     http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			auth.now = func() time.Time { return now.Add(tc.After) }
+
+			req := httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader(tc.Body))
+			req.RemoteAddr = "192.0.2.10:443"
+			if tc.RemoteAddr != "" {
+				req.RemoteAddr = tc.RemoteAddr
+			}
+			if tc.Username != "" {
+				req.SetBasicAuth(tc.Username, tc.Password)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if got, want := w.Code, tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCallbackAuthForwardedFor(t *testing.T) {
+	auth, err := NewCallbackAuth(
+		WithAllowedSources("192.0.2.0/24"),
+		WithForwardedFor(1),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	h := auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	testCases := map[string]struct {
+		ForwardedFor []string
+		Want         int
+	}{
+		"appended by proxy": {
+			ForwardedFor: []string{"192.0.2.10"},
+			Want:         http.StatusNoContent,
+		},
+		"client entries ignored": {
+			ForwardedFor: []string{"203.0.113.5, 192.0.2.10"},
+			Want:         http.StatusNoContent,
+		},
+		"spoofed left-most entry": {
+			ForwardedFor: []string{"192.0.2.10, 203.0.113.5"},
+			Want:         http.StatusForbidden,
+		},
+		"spoofed header line": {
+			ForwardedFor: []string{"192.0.2.10", "203.0.113.5"},
+			Want:         http.StatusForbidden,
+		},
+		"missing": {
+			Want: http.StatusForbidden,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader("{}"))
+			req.RemoteAddr = "10.0.0.1:1234"
+			for _, v := range tc.ForwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if got, want := w.Code, tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCallbackAuthInvalidSource(t *testing.T) {
+	if _, err := NewCallbackAuth(WithAllowedSources("not-an-address")); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
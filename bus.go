@@ -0,0 +1,281 @@
+package bandwidth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy determines what a subscription does with an event when its buffer is full
+type Policy int
+
+const (
+	Block      Policy = iota // Block - wait for the subscriber; delays the publisher
+	DropOldest               // DropOldest - discard the oldest buffered event
+	DropNewest               // DropNewest - discard the event being published
+)
+
+// Filter selects events for a subscription.  Empty fields match every event; an event must
+// match every field set.
+type Filter struct {
+	EventTypes   []string // EventTypes - matches any of the given event types
+	CallId       string
+	ConferenceId string
+	Tag          string
+}
+
+// Match returns true if the event matches the filter
+func (f Filter) Match(event Event) bool {
+	if len(f.EventTypes) > 0 {
+		var found bool
+		for _, eventType := range f.EventTypes {
+			if eventType == event.GetEventType() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.CallId != "" && f.CallId != event.GetCallId() {
+		return false
+	}
+	if f.ConferenceId != "" {
+		e, ok := event.(ConferenceEvent)
+		if !ok || e.GetConferenceId() != f.ConferenceId {
+			return false
+		}
+	}
+	if f.Tag != "" && f.Tag != event.GetTag() {
+		return false
+	}
+	return true
+}
+
+type subscriptionOptions struct {
+	bufferSize int
+	policy     Policy
+}
+
+// SubscribeOption configures a Subscription
+type SubscribeOption func(*subscriptionOptions)
+
+// WithBufferSize sets the number of events buffered for the subscriber; defaults to 64
+func WithBufferSize(n int) SubscribeOption {
+	return func(o *subscriptionOptions) {
+		o.bufferSize = n
+	}
+}
+
+// WithPolicy sets what happens when the buffer is full; defaults to DropOldest so a slow subscriber
+// never delays the publisher
+func WithPolicy(policy Policy) SubscribeOption {
+	return func(o *subscriptionOptions) {
+		o.policy = policy
+	}
+}
+
+// Subscription receives the events that match its filter on C.  C is closed once the
+// subscription is closed.
+type Subscription struct {
+	C <-chan Event
+
+	bus     *EventBus
+	id      int
+	filter  Filter
+	options subscriptionOptions
+	queue   chan Event
+	done    chan struct{}
+	once    sync.Once
+	dropped int64
+}
+
+// Dropped returns the number of events discarded because the buffer was full
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Close stops the subscription and closes C.  Buffered events not yet received are discarded.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.remove(s.id)
+		close(s.done)
+	})
+}
+
+// send delivers event according to the subscription's policy
+func (s *Subscription) send(ctx context.Context, event Event) error {
+	switch s.options.policy {
+	case DropNewest:
+		select {
+		case s.queue <- event:
+		case <-s.done:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case s.queue <- event:
+				return nil
+			case <-s.done:
+				return nil
+			default:
+			}
+			select {
+			case <-s.queue:
+				atomic.AddInt64(&s.dropped, 1)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case s.queue <- event:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// forward moves events from the queue to the subscriber until the subscription is closed
+func (s *Subscription) forward(c chan Event) {
+	defer close(c)
+	for {
+		select {
+		case <-s.done:
+			return
+		case event := <-s.queue:
+			select {
+			case c <- event:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// EventBus fans callbacks out to subscribers within a process.  Pass it to a CallbackRouter
+// with WithEventBus to publish every callback handled.
+//
+//	bus := bandwidth.NewEventBus()
+//	sub := bus.Subscribe(ctx, bandwidth.Filter{EventTypes: []string{"disconnect"}})
+//	for event := range sub.C {
+//		...
+//	}
+//
+// EventBus is safe for concurrent use.
+type EventBus struct {
+	mu     sync.Mutex
+	nextId int
+	subs   map[int]*Subscription
+	closed bool
+}
+
+// NewEventBus returns an EventBus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subs: map[int]*Subscription{},
+	}
+}
+
+// Subscribe returns a subscription to the events that match filter.  The subscription is
+// closed when ctx is done, Close is called, or the bus is closed.
+func (b *EventBus) Subscribe(ctx context.Context, filter Filter, opts ...SubscribeOption) *Subscription {
+	options := subscriptionOptions{
+		bufferSize: 64,
+		policy:     DropOldest,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.bufferSize < 1 {
+		options.bufferSize = 1
+	}
+
+	c := make(chan Event)
+	s := &Subscription{
+		C:       c,
+		bus:     b,
+		filter:  filter,
+		options: options,
+		queue:   make(chan Event, options.bufferSize),
+		done:    make(chan struct{}),
+	}
+	go s.forward(c)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		s.id = -1
+		s.Close()
+		return s
+	}
+	s.id = b.nextId
+	b.nextId++
+	b.subs[s.id] = s
+	b.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+
+	return s
+}
+
+// Publish delivers event to every matching subscription, in the order they subscribed.  Publish
+// waits for subscriptions with the Block policy until ctx is done; a subscription that times out
+// misses the event but does not prevent delivery to the others.  Returns the first error.
+func (b *EventBus) Publish(ctx context.Context, event Event) error {
+	b.mu.Lock()
+	var subs []*Subscription
+	for _, s := range b.subs {
+		if s.filter.Match(event) {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.Unlock()
+
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].id < subs[j].id
+	})
+
+	var first error
+	for _, s := range subs {
+		if err := s.send(ctx, event); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close closes every subscription; later subscriptions are closed immediately
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	var subs []*Subscription
+	for _, s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.Close()
+	}
+}
+
+func (b *EventBus) remove(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subs, id)
+}
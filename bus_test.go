@@ -0,0 +1,227 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	select {
+	case event, ok := <-sub.C:
+		if !ok {
+			t.Fatalf("got closed; want event")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatalf("got timeout; want event")
+	}
+	return nil
+}
+
+func TestFilter(t *testing.T) {
+	joined := &ConferenceMemberJoinEvent{EventType: "conferenceMemberJoin", ConferenceId: "conf-1", CallId: "c-1", Tag: "a"}
+	disconnect := &DisconnectEvent{EventType: "disconnect", CallId: "c-1", Tag: "a"}
+
+	testCases := map[string]struct {
+		Filter Filter
+		Event  Event
+		Want   bool
+	}{
+		"empty":           {Filter: Filter{}, Event: disconnect, Want: true},
+		"type":            {Filter: Filter{EventTypes: []string{"answer", "disconnect"}}, Event: disconnect, Want: true},
+		"other type":      {Filter: Filter{EventTypes: []string{"answer"}}, Event: disconnect, Want: false},
+		"call":            {Filter: Filter{CallId: "c-1"}, Event: disconnect, Want: true},
+		"other call":      {Filter: Filter{CallId: "c-2"}, Event: disconnect, Want: false},
+		"conference":      {Filter: Filter{ConferenceId: "conf-1"}, Event: joined, Want: true},
+		"not conference":  {Filter: Filter{ConferenceId: "conf-1"}, Event: disconnect, Want: false},
+		"tag":             {Filter: Filter{Tag: "a"}, Event: disconnect, Want: true},
+		"every field set": {Filter: Filter{CallId: "c-1", Tag: "b"}, Event: disconnect, Want: false},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got, want := tc.Filter.Match(tc.Event), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEventBusPolicies(t *testing.T) {
+	ctx := context.Background()
+	bus := NewEventBus()
+	defer bus.Close()
+
+	newest := bus.Subscribe(ctx, Filter{}, WithBufferSize(1), WithPolicy(DropNewest))
+	oldest := bus.Subscribe(ctx, Filter{}, WithBufferSize(1), WithPolicy(DropOldest))
+
+	// the first event may be held by the forwarding goroutine, so publish enough to overflow
+	for _, callId := range []string{"c-1", "c-2", "c-3", "c-4"} {
+		if err := bus.Publish(ctx, &AnswerEvent{EventType: "answer", CallId: callId}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	if newest.Dropped() == 0 {
+		t.Fatalf("got 0; want dropped events")
+	}
+	if got, want := receive(t, newest).GetCallId(), "c-1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if oldest.Dropped() == 0 {
+		t.Fatalf("got 0; want dropped events")
+	}
+	var last string
+	for i := int64(0); i < 4-oldest.Dropped(); i++ {
+		last = receive(t, oldest).GetCallId()
+	}
+	if got, want := last, "c-4"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestEventBusBlock(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+
+	bus.Subscribe(context.Background(), Filter{}, WithBufferSize(1), WithPolicy(Block))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = bus.Publish(ctx, &AnswerEvent{EventType: "answer"})
+	}
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestEventBusShutdown(t *testing.T) {
+	bus := NewEventBus()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sub := bus.Subscribe(ctx, Filter{})
+	cancel()
+
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Fatalf("got event; want closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("got timeout; want closed")
+	}
+
+	bus.Close()
+	late := bus.Subscribe(context.Background(), Filter{})
+	if _, ok := <-late.C; ok {
+		t.Fatalf("got event; want closed")
+	}
+}
+
+func TestCallbackRouterEventBus(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+	sub := bus.Subscribe(context.Background(), Filter{EventTypes: []string{"disconnect"}})
+
+	router := NewCallbackRouter(WithEventBus(bus), WithSeenStore(NewMemoryStore(10)))
+	body := `{"eventType":"disconnect","callId":"c-1","eventTime":"2020-01-02T03:04:05Z"}`
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader(body)))
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	if got, want := receive(t, sub).GetCallId(), "c-1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	select {
+	case event := <-sub.C:
+		t.Fatalf("got %v; want duplicate not published", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestEventBusPublishesPastBlockedSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+
+	// the blocked subscriber subscribes first, so it is sent each event before the others
+	bus.Subscribe(context.Background(), Filter{}, WithBufferSize(1), WithPolicy(Block))
+	subs := []*Subscription{
+		bus.Subscribe(context.Background(), Filter{}),
+		bus.Subscribe(context.Background(), Filter{}, WithPolicy(DropNewest)),
+	}
+
+	var timeouts int
+	for _, callId := range []string{"c-1", "c-2", "c-3", "c-4"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if err := bus.Publish(ctx, &AnswerEvent{EventType: "answer", CallId: callId}); err == context.DeadlineExceeded {
+			timeouts++
+		} else if err != nil {
+			t.Fatalf("got %v; want nil or %v", err, context.DeadlineExceeded)
+		}
+		cancel()
+	}
+	if timeouts == 0 {
+		t.Fatalf("got 0; want blocked subscriber to time out")
+	}
+
+	for _, sub := range subs {
+		for _, want := range []string{"c-1", "c-2", "c-3", "c-4"} {
+			if got := receive(t, sub).GetCallId(); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestCallbackRouterEventBusAfterHandler(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+
+	// a full subscriber with the Block policy delays the response by no more than the timeout,
+	// and does not prevent delivery to subscribers after it
+	bus.Subscribe(context.Background(), Filter{}, WithBufferSize(1), WithPolicy(Block))
+	sub := bus.Subscribe(context.Background(), Filter{})
+
+	var calls int
+	router := NewCallbackRouter(WithEventBus(bus), WithPublishTimeout(10*time.Millisecond))
+	router.OnAnswer(func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		calls++
+		if calls == 1 {
+			return bxml.Response{}, errors.New("boom")
+		}
+		return bxml.NewResponse().Hangup(), ctx.Err()
+	})
+
+	codes := []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK, http.StatusOK}
+	for i, want := range codes {
+		body := fmt.Sprintf(`{"eventType":"answer","callId":"c-%v","eventTime":"2020-01-02T03:04:05Z"}`, i)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader(body)))
+		if got := w.Code; got != want {
+			t.Fatalf("got %v; want %v at %v", got, want, i)
+		}
+	}
+
+	// the failed callback was not published; each success was, in order
+	for _, want := range []string{"c-1", "c-2", "c-3"} {
+		if got := receive(t, sub).GetCallId(); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
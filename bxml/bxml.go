@@ -5,7 +5,38 @@ import (
 	"io"
 )
 
-// Write the responses
+// Verb is implemented by every BXML verb
+type Verb interface {
+	isVerb()
+}
+
+func (Bridge) isVerb()             {}
+func (Conference) isVerb()         {}
+func (Forward) isVerb()            {}
+func (Gather) isVerb()             {}
+func (Hangup) isVerb()             {}
+func (Pause) isVerb()              {}
+func (PauseRecording) isVerb()     {}
+func (PlayAudio) isVerb()          {}
+func (Record) isVerb()             {}
+func (Redirect) isVerb()           {}
+func (ResumeRecording) isVerb()    {}
+func (Ring) isVerb()               {}
+func (SendDtmf) isVerb()           {}
+func (SpeakSentence) isVerb()      {}
+func (StartGather) isVerb()        {}
+func (StartRecording) isVerb()     {}
+func (StartStream) isVerb()        {}
+func (StartTranscription) isVerb() {}
+func (StopGather) isVerb()         {}
+func (StopRecording) isVerb()      {}
+func (StopStream) isVerb()         {}
+func (StopTranscription) isVerb()  {}
+func (Tag) isVerb()                {}
+func (Transfer) isVerb()           {}
+
+// Write the responses.  vv may contain any value that encodes as a BXML verb, including verbs
+// this package does not define; use Response to build documents from Verb values.
 func Write(w io.Writer, vv ...interface{}) error {
 	if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>`); err != nil {
 		return err
@@ -28,6 +59,19 @@ func Write(w io.Writer, vv ...interface{}) error {
 	return nil
 }
 
+// Verbs holds nested verbs, such as the prompts of a <Gather>, each encoded as its own element
+type Verbs []Verb
+
+// MarshalXML encodes each verb using its own element name
+func (vv Verbs) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	for _, v := range vv {
+		if err := e.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Bridge - https://dev.bandwidth.com/voice/bxml/verbs/bridge.html
 type Bridge struct {
 	BridgeCompleteFallbackMethod       string `xml:"bridgeCompleteFallbackMethod,attr,omitempty"`       // (optional) The HTTP method to use to deliver the Bridge Complete callback to bridgeCompleteFallbackUrl. GET or POST. Default value is POST.
@@ -43,6 +87,7 @@ type Bridge struct {
 	Password                           string `xml:"password,attr,omitempty"`                           // (optional) The password to send in the HTTP request to bridgeCompleteUrl and to bridgeTargetCompleteUrl.
 	Tag                                string `xml:"tag,attr,omitempty"`                                // (optional) A custom string that will be sent with the bridgeComplete callback and all future callbacks of the call unless overwritten by a future tag attribute or cleared.
 	Username                           string `xml:"username,attr,omitempty"`                           // (optional) The username to send in the HTTP request to bridgeCompleteUrl and to bridgeTargetCompleteUrl.
+	TargetCall                         string `xml:",chardata"`                                         // The callId of the call to be bridged.
 }
 
 // Conference - https://dev.bandwidth.com/voice/bxml/verbs/conference.html
@@ -60,6 +105,7 @@ type Conference struct {
 	Password                      string `xml:"password,attr,omitempty"`                      // (optional) The password to send in the HTTP request to conferenceEventUrl.
 	Tag                           string `xml:"tag,attr,omitempty"`                           // (optional) A custom string that will be sent with these and all future callbacks unless overwritten by a future tag attribute or cleared.
 	Username                      string `xml:"username,attr,omitempty"`                      // (optional) The username to send in the HTTP request to conferenceEventUrl.
+	Name                          string `xml:",chardata"`                                    // The name of the conference. Can contain up to 100 characters of letters, numbers, and the symbols -, _, and .
 }
 
 // https://dev.bandwidth.com/voice/bxml/verbs/forward.html
@@ -87,6 +133,7 @@ type Gather struct {
 	Tag                  string `xml:"tag,attr,omitempty"`                  // 	(optional) A custom string that will be sent with this and all future callbacks unless overwritten by a future tag attribute or cleared.
 	TerminatingDigits    string `xml:"terminatingDigits,attr,omitempty"`    // 	(optional) When any of these digits are pressed, it will terminate the Gather. Default value is "", which disables this feature.
 	Username             string `xml:"username,attr,omitempty"`             // 	(optional) The username to send in the HTTP request to gatherUrl.
+	Prompts              Verbs  `xml:"Prompts"`                             // 	(optional) <SpeakSentence> and <PlayAudio> verbs played, in order, while digits are collected.
 }
 
 // Hangup - https://dev.bandwidth.com/voice/bxml/verbs/hangup.html
@@ -106,6 +153,7 @@ type PauseRecording struct {
 type PlayAudio struct {
 	Username string `xml:"username,attr,omitempty"` // (optional) The username to send in the HTTP request to audioUri.
 	Password string `xml:"password,attr,omitempty"` // (optional) The password to send in the HTTP request to audioUri.
+	AudioUri string `xml:",chardata"`               // The URL of the audio file to play. May be a relative URL.
 }
 
 // Record - https://dev.bandwidth.com/voice/bxml/verbs/record.html
@@ -156,13 +204,25 @@ type Ring struct {
 type SendDtmf struct {
 	ToneDuration string `xml:"toneDuration,attr,omitempty"` // 	(optional) The length (in milliseconds) of each DTMF tone. Default value is 200. Range: decimal values between 50 - 5000.
 	ToneInterval string `xml:"toneInterval,attr,omitempty"` // 	(optional) The duration of silence (in milliseconds) following each DTMF tone. Default value is 400. Range: decimal values between 50 - 5000.
+	Digits       string `xml:",chardata"`                   // 	The digits to send; 0-9, *, #, w (0.5 second pause) and W (1 second pause).
 }
 
 // SpeakSentence - https://dev.bandwidth.com/voice/bxml/verbs/speakSentence.html
 type SpeakSentence struct {
-	Voice  string `xml:"voice,attr,omitempty"`  // 	Selects the voice of the speaker. Consult the voice column in the below table for valid values.
-	Gender string `xml:"gender,attr,omitempty"` // 	Selects the gender of the speaker. Valid values are "male" or "female".
-	Locale string `xml:"locale,attr,omitempty"` // 	Selects the locale of the speaker. Consult locale column in the below table for valid values.
+	Voice    string `xml:"voice,attr,omitempty"`  // 	Selects the voice of the speaker. Consult the voice column in the below table for valid values.
+	Gender   string `xml:"gender,attr,omitempty"` // 	Selects the gender of the speaker. Valid values are "male" or "female".
+	Locale   string `xml:"locale,attr,omitempty"` // 	Selects the locale of the speaker. Consult locale column in the below table for valid values.
+	Sentence string `xml:",chardata"`             // 	The text to speak.
+	SSML     string `xml:",innerxml"`             // 	(optional) SSML to speak, see SSML. Written verbatim and so must already be escaped.
+}
+
+// StartGather - https://dev.bandwidth.com/voice/bxml/verbs/startGather.html
+type StartGather struct {
+	DtmfUrl    string `xml:"dtmfUrl,attr,omitempty"`    // 	URL to send the DTMF event to. May be a relative URL.
+	DtmfMethod string `xml:"dtmfMethod,attr,omitempty"` // 	(optional) The HTTP method to use for the request to dtmfUrl. GET or POST. Default value is POST.
+	Username   string `xml:"username,attr,omitempty"`   // 	(optional) The username to send in the HTTP request to dtmfUrl.
+	Password   string `xml:"password,attr,omitempty"`   // 	(optional) The password to send in the HTTP request to dtmfUrl.
+	Tag        string `xml:"tag,attr,omitempty"`        // 	(optional) A custom string that will be sent with this and all future callbacks unless overwritten by a future tag attribute or cleared.
 }
 
 // StartRecording - https://dev.bandwidth.com/voice/bxml/verbs/startRecording.html
@@ -179,23 +239,119 @@ type StartRecording struct {
 	MultiChannel                 string `xml:"multiChannel,attr,omitempty"`                 //     (optional) A boolean value indicating whether or not the recording file should separate each side of the call into its own audio channel.Default value is false.
 }
 
+// StartStream - https://dev.bandwidth.com/voice/bxml/verbs/startStream.html
+type StartStream struct {
+	Name              string        `xml:"name,attr,omitempty"`              // 	(optional) A name to refer to this stream by. Used when sending <StopStream>. If not provided, it will default to the generated stream id as sent in the Media Stream Started webhook.
+	Tracks            string        `xml:"tracks,attr,omitempty"`            // 	(optional) The part of the call to send a stream from. inbound, outbound or both. Default is inbound.
+	Destination       string        `xml:"destination,attr,omitempty"`       // 	A websocket URI to send the stream to. The audio from the specified tracks will be sent via websocket to this URL as base64-encoded PCMU/G711 audio.
+	StreamEventUrl    string        `xml:"streamEventUrl,attr,omitempty"`    // 	(optional) URL to send the associated Webhook events to during this stream's lifetime. Does not accept BXML. May be a relative URL.
+	StreamEventMethod string        `xml:"streamEventMethod,attr,omitempty"` // 	(optional) The HTTP method to use for the request to streamEventUrl. GET or POST. Default value is POST.
+	Username          string        `xml:"username,attr,omitempty"`          // 	(optional) The username to send in the HTTP request to streamEventUrl.
+	Password          string        `xml:"password,attr,omitempty"`          // 	(optional) The password to send in the HTTP request to streamEventUrl.
+	StreamParams      []StreamParam `xml:"StreamParam"`                      // 	(optional) Up to 12 custom parameters sent to the destination in the start message.
+}
+
+// StreamParam - a custom parameter of a <StartStream>
+type StreamParam struct {
+	Name  string `xml:"name,attr,omitempty"`  // 	The name of this parameter, up to 256 characters.
+	Value string `xml:"value,attr,omitempty"` // 	The value of this parameter, up to 2048 characters.
+}
+
+// StartTranscription - https://dev.bandwidth.com/voice/bxml/verbs/startTranscription.html
+type StartTranscription struct {
+	Name                     string            `xml:"name,attr,omitempty"`                     // 	(optional) A name to refer to this transcription by. Used when sending <StopTranscription>. If not provided, it will default to the generated transcription id.
+	Tracks                   string            `xml:"tracks,attr,omitempty"`                   // 	(optional) The part of the call to send a transcription from. inbound, outbound or both. Default is inbound.
+	TranscriptionEventUrl    string            `xml:"transcriptionEventUrl,attr,omitempty"`    // 	(optional) URL to send the associated Webhook events to during this real-time transcription's lifetime. Does not accept BXML. May be a relative URL.
+	TranscriptionEventMethod string            `xml:"transcriptionEventMethod,attr,omitempty"` // 	(optional) The HTTP method to use for the request to transcriptionEventUrl. GET or POST. Default value is POST.
+	Username                 string            `xml:"username,attr,omitempty"`                 // 	(optional) The username to send in the HTTP request to transcriptionEventUrl.
+	Password                 string            `xml:"password,attr,omitempty"`                 // 	(optional) The password to send in the HTTP request to transcriptionEventUrl.
+	Destination              string            `xml:"destination,attr,omitempty"`              // 	(optional) A websocket URI to send the transcription to. If not provided, transcriptions are sent to transcriptionEventUrl.
+	Stabilized               *bool             `xml:"stabilized,attr,omitempty"`               // 	(optional) Whether to send transcription update events only once the transcription is stable. Default is true; use Bool(false) to disable.
+	CustomParameters         []CustomParameter `xml:"CustomParameter"`                         // 	(optional) Up to 12 custom parameters sent with each transcription event.
+}
+
+// Bool returns a pointer to v, for optional attributes whose default is true
+func Bool(v bool) *bool {
+	return &v
+}
+
+// CustomParameter - a custom parameter of a <StartTranscription>
+type CustomParameter struct {
+	Name  string `xml:"name,attr,omitempty"`  // 	The name of this parameter, up to 256 characters.
+	Value string `xml:"value,attr,omitempty"` // 	The value of this parameter, up to 2048 characters.
+}
+
+// StopGather - https://dev.bandwidth.com/voice/bxml/verbs/stopGather.html
+type StopGather struct {
+}
+
 // StopRecording -
 type StopRecording struct {
 }
 
+// StopStream - https://dev.bandwidth.com/voice/bxml/verbs/stopStream.html
+type StopStream struct {
+	Name string `xml:"name,attr,omitempty"` // 	The name of the stream to stop, as set by <StartStream>.
+}
+
+// StopTranscription - https://dev.bandwidth.com/voice/bxml/verbs/stopTranscription.html
+type StopTranscription struct {
+	Name string `xml:"name,attr,omitempty"` // 	The name of the real-time transcription to stop, as set by <StartTranscription>.
+}
+
+// Tag - https://dev.bandwidth.com/voice/bxml/verbs/tag.html
+type Tag struct {
+	Value string `xml:",chardata"` // 	The tag sent with all future callbacks; an empty value clears the tag.
+}
+
 // Transfer - https://dev.bandwidth.com/voice/bxml/verbs/transfer.html
 type Transfer struct {
-	TransferCallerId               string `xml:"transferCallerId,attr,omitempty"`               // 	(optional) The caller ID to use when the call is transferred, if different. Must be in E.164 format (e.g. +15555555555).
-	CallTimeout                    string `xml:"callTimeout,attr,omitempty"`                    // 	(optional) This is the timeout (in seconds) for the callee to answer the call. Range: decimal values between 1 - 300. Default value is 30 seconds.
-	TransferCompleteUrl            string `xml:"transferCompleteUrl,attr,omitempty"`            // 	(optional) URL to send the Transfer Complete event to and request new BXML. Optional but recommended. See below for further details. May be a relative URL.
-	TransferCompleteMethod         string `xml:"transferCompleteMethod,attr,omitempty"`         // 	(optional) The HTTP method to use for the request to transferCompleteUrl. GET or POST. Default value is POST.
-	TransferCompleteFallbackUrl    string `xml:"transferCompleteFallbackUrl,attr,omitempty"`    // 	(optional) A fallback url which, if provided, will be used to retry the Transfer Complete callback delivery in case transferCompleteUrl fails to respond.
-	TransferCompleteFallbackMethod string `xml:"transferCompleteFallbackMethod,attr,omitempty"` // 	(optional) The HTTP method to use to deliver the Transfer Complete callback to transferCompleteFallbackUrl. GET or POST. Default value is POST.
-	Username                       string `xml:"username,attr,omitempty"`                       // 	(optional) The username to send in the HTTP request to transferCompleteUrl.
-	Password                       string `xml:"password,attr,omitempty"`                       // 	(optional) The password to send in the HTTP request to transferCompleteUrl.
-	FallbackUsername               string `xml:"fallbackUsername,attr,omitempty"`               // 	(optional) The username to send in the HTTP request to transferCompleteFallbackUrl.
-	FallbackPassword               string `xml:"fallbackPassword,attr,omitempty"`               // 	(optional) The password to send in the HTTP request to transferCompleteFallbackUrl.
-	Tag                            string `xml:"tag,attr,omitempty"`                            // 	(optional) A custom string that will be sent with this and all future callbacks unless overwritten by a future tag attribute or cleared.
-	DiversionTreatment             string `xml:"diversionTreatment,attr,omitempty"`             // 	(optional) Can be any of the following:
-	DiversionReason                string `xml:"diversionReason,attr,omitempty"`                // 	(optional) Can be any of the following values:
+	TransferCallerId               string        `xml:"transferCallerId,attr,omitempty"`               // 	(optional) The caller ID to use when the call is transferred, if different. Must be in E.164 format (e.g. +15555555555).
+	CallTimeout                    string        `xml:"callTimeout,attr,omitempty"`                    // 	(optional) This is the timeout (in seconds) for the callee to answer the call. Range: decimal values between 1 - 300. Default value is 30 seconds.
+	TransferCompleteUrl            string        `xml:"transferCompleteUrl,attr,omitempty"`            // 	(optional) URL to send the Transfer Complete event to and request new BXML. Optional but recommended. See below for further details. May be a relative URL.
+	TransferCompleteMethod         string        `xml:"transferCompleteMethod,attr,omitempty"`         // 	(optional) The HTTP method to use for the request to transferCompleteUrl. GET or POST. Default value is POST.
+	TransferCompleteFallbackUrl    string        `xml:"transferCompleteFallbackUrl,attr,omitempty"`    // 	(optional) A fallback url which, if provided, will be used to retry the Transfer Complete callback delivery in case transferCompleteUrl fails to respond.
+	TransferCompleteFallbackMethod string        `xml:"transferCompleteFallbackMethod,attr,omitempty"` // 	(optional) The HTTP method to use to deliver the Transfer Complete callback to transferCompleteFallbackUrl. GET or POST. Default value is POST.
+	Username                       string        `xml:"username,attr,omitempty"`                       // 	(optional) The username to send in the HTTP request to transferCompleteUrl.
+	Password                       string        `xml:"password,attr,omitempty"`                       // 	(optional) The password to send in the HTTP request to transferCompleteUrl.
+	FallbackUsername               string        `xml:"fallbackUsername,attr,omitempty"`               // 	(optional) The username to send in the HTTP request to transferCompleteFallbackUrl.
+	FallbackPassword               string        `xml:"fallbackPassword,attr,omitempty"`               // 	(optional) The password to send in the HTTP request to transferCompleteFallbackUrl.
+	Tag                            string        `xml:"tag,attr,omitempty"`                            // 	(optional) A custom string that will be sent with this and all future callbacks unless overwritten by a future tag attribute or cleared.
+	DiversionTreatment             string        `xml:"diversionTreatment,attr,omitempty"`             // 	(optional) Can be any of the following:
+	DiversionReason                string        `xml:"diversionReason,attr,omitempty"`                // 	(optional) Can be any of the following values:
+	PhoneNumbers                   []PhoneNumber `xml:"PhoneNumber"`                                   // 	(optional) Phone numbers to ring; the first to answer is connected.
+	SipUris                        []SipUri      `xml:"SipUri"`                                        // 	(optional) SIP URIs to ring; the first to answer is connected.
+}
+
+// PhoneNumber - https://dev.bandwidth.com/voice/bxml/verbs/phoneNumber.html
+type PhoneNumber struct {
+	TransferAnswerUrl            string `xml:"transferAnswerUrl,attr,omitempty"`            // 	(optional) URL, if any, to send the Transfer Answer event to and request BXML to be executed for the called party before the call is bridged. May be a relative URL.
+	TransferAnswerMethod         string `xml:"transferAnswerMethod,attr,omitempty"`         // 	(optional) The HTTP method to use for the request to transferAnswerUrl. GET or POST. Default value is POST.
+	TransferAnswerFallbackUrl    string `xml:"transferAnswerFallbackUrl,attr,omitempty"`    // 	(optional) A fallback url which, if provided, will be used to retry the Transfer Answer callback delivery in case transferAnswerUrl fails to respond.
+	TransferAnswerFallbackMethod string `xml:"transferAnswerFallbackMethod,attr,omitempty"` // 	(optional) The HTTP method to use to deliver the Transfer Answer callback to transferAnswerFallbackUrl. GET or POST. Default value is POST.
+	TransferDisconnectUrl        string `xml:"transferDisconnectUrl,attr,omitempty"`        // 	(optional) URL, if any, to send the Transfer Disconnect event to. This event will be sent regardless of how the transfer ends and may not be responded to with BXML. May be a relative URL.
+	TransferDisconnectMethod     string `xml:"transferDisconnectMethod,attr,omitempty"`     // 	(optional) The HTTP method to use for the request to transferDisconnectUrl. GET or POST. Default value is POST.
+	Username                     string `xml:"username,attr,omitempty"`                     // 	(optional) The username to send in the HTTP request to transferAnswerUrl and transferDisconnectUrl.
+	Password                     string `xml:"password,attr,omitempty"`                     // 	(optional) The password to send in the HTTP request to transferAnswerUrl and transferDisconnectUrl.
+	FallbackUsername             string `xml:"fallbackUsername,attr,omitempty"`             // 	(optional) The username to send in the HTTP request to transferAnswerFallbackUrl.
+	FallbackPassword             string `xml:"fallbackPassword,attr,omitempty"`             // 	(optional) The password to send in the HTTP request to transferAnswerFallbackUrl.
+	Tag                          string `xml:"tag,attr,omitempty"`                          // 	(optional) A custom string that will be sent with these and all future callbacks unless overwritten by a future tag attribute or cleared.
+	Number                       string `xml:",chardata"`                                   // 	The phone number to ring, in E.164 format (e.g. +15555555555).
+}
+
+// SipUri - https://dev.bandwidth.com/voice/bxml/verbs/sipUri.html
+type SipUri struct {
+	Uui                          string `xml:"uui,attr,omitempty"`                          // 	(optional) The value of the User-To-User header to send within the initial INVITE. Must include the encoding parameter as specified in RFC 7433. Only base64 and jwt encoding are currently allowed. This value, including the encoding specifier, may not exceed 256 characters.
+	TransferAnswerUrl            string `xml:"transferAnswerUrl,attr,omitempty"`            // 	(optional) URL, if any, to send the Transfer Answer event to and request BXML to be executed for the called party before the call is bridged. May be a relative URL.
+	TransferAnswerMethod         string `xml:"transferAnswerMethod,attr,omitempty"`         // 	(optional) The HTTP method to use for the request to transferAnswerUrl. GET or POST. Default value is POST.
+	TransferAnswerFallbackUrl    string `xml:"transferAnswerFallbackUrl,attr,omitempty"`    // 	(optional) A fallback url which, if provided, will be used to retry the Transfer Answer callback delivery in case transferAnswerUrl fails to respond.
+	TransferAnswerFallbackMethod string `xml:"transferAnswerFallbackMethod,attr,omitempty"` // 	(optional) The HTTP method to use to deliver the Transfer Answer callback to transferAnswerFallbackUrl. GET or POST. Default value is POST.
+	TransferDisconnectUrl        string `xml:"transferDisconnectUrl,attr,omitempty"`        // 	(optional) URL, if any, to send the Transfer Disconnect event to. This event will be sent regardless of how the transfer ends and may not be responded to with BXML. May be a relative URL.
+	TransferDisconnectMethod     string `xml:"transferDisconnectMethod,attr,omitempty"`     // 	(optional) The HTTP method to use for the request to transferDisconnectUrl. GET or POST. Default value is POST.
+	Username                     string `xml:"username,attr,omitempty"`                     // 	(optional) The username to send in the HTTP request to transferAnswerUrl and transferDisconnectUrl.
+	Password                     string `xml:"password,attr,omitempty"`                     // 	(optional) The password to send in the HTTP request to transferAnswerUrl and transferDisconnectUrl.
+	FallbackUsername             string `xml:"fallbackUsername,attr,omitempty"`             // 	(optional) The username to send in the HTTP request to transferAnswerFallbackUrl.
+	FallbackPassword             string `xml:"fallbackPassword,attr,omitempty"`             // 	(optional) The password to send in the HTTP request to transferAnswerFallbackUrl.
+	Tag                          string `xml:"tag,attr,omitempty"`                          // 	(optional) A custom string that will be sent with these and all future callbacks unless overwritten by a future tag attribute or cleared.
+	Uri                          string `xml:",chardata"`                                   // 	The SIP URI to ring, e.g. sip:user@server.com
 }
@@ -2,6 +2,7 @@ package bxml
 
 import (
 	"bytes"
+	"encoding/xml"
 	"testing"
 )
 
@@ -22,3 +23,54 @@ func TestWrite(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestWriteCustomVerb(t *testing.T) {
+	type Beep struct {
+		XMLName xml.Name `xml:"Beep"`
+		Count   int      `xml:"count,attr"`
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := Write(buf, Beep{Count: 2}, Hangup{}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?><Response><Beep count="2"></Beep><Hangup></Hangup></Response>`
+	if got := buf.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWriteNested(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	err := Write(buf,
+		Gather{
+			MaxDigits: 1,
+			GatherUrl: "/menu",
+			Prompts: Verbs{
+				SpeakSentence{Voice: "julie", Sentence: "Press 1 for sales & support."},
+				PlayAudio{AudioUri: "https://example.com/hold.wav"},
+			},
+		},
+		Transfer{
+			TransferCallerId: "+18005551212",
+			PhoneNumbers:     []PhoneNumber{{Tag: "sales", Number: "+18885551212"}},
+			SipUris:          []SipUri{{Uui: "abc;encoding=base64", Uri: "sip:sales@example.com"}},
+		},
+		SendDtmf{Digits: "12w#"},
+		Conference{Name: "my-conference"},
+		Bridge{TargetCall: "c-95ac8d6e-1a31c52e-b38f-4198-93c1-51633ec68f8d"},
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?><Response>` +
+		`<Gather gatherUrl="/menu" maxDigits="1"><SpeakSentence voice="julie">Press 1 for sales &amp; support.</SpeakSentence><PlayAudio>https://example.com/hold.wav</PlayAudio></Gather>` +
+		`<Transfer transferCallerId="+18005551212"><PhoneNumber tag="sales">+18885551212</PhoneNumber><SipUri uui="abc;encoding=base64">sip:sales@example.com</SipUri></Transfer>` +
+		`<SendDtmf>12w#</SendDtmf>` +
+		`<Conference>my-conference</Conference>` +
+		`<Bridge>c-95ac8d6e-1a31c52e-b38f-4198-93c1-51633ec68f8d</Bridge>` +
+		`</Response>`
+	if got := buf.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,419 @@
+// Package bxmltest provides helpers for testing BXML; golden files, equality that ignores
+// attribute order and formatting, and assertions about the verbs a handler returns.
+//
+//	func TestMenu(t *testing.T) {
+//		doc := bxmltest.Serve(t, menuHandler, bxmltest.Post("/answer", answerEvent))
+//		doc.ContainsGather(bxmltest.GatherMatch{MaxDigits: 4, Says: "Enter your pin"})
+//		doc.LastVerbIs("Hangup")
+//		bxmltest.Golden(t, "menu.golden", doc.Body)
+//	}
+//
+// Golden files are rewritten with the canonical form of the document when tests are run with
+// -update-golden.
+package bxmltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+// Update rewrites golden files rather than comparing against them; set by -update-golden
+var Update bool
+
+func init() {
+	flag.BoolVar(&Update, "update-golden", false, "rewrite bxmltest golden files")
+}
+
+// Canonicalize returns an indented form of an xml document in which attributes are sorted by
+// name and whitespace-only text between verbs and other elements that don't contain text is
+// removed.  Text in mixed content, such as a <SpeakSentence> and its SSML, is kept verbatim,
+// including whitespace between inline elements, except that whitespace next to the tags of its
+// enclosing element is trimmed; elements containing text are written on a single line.  The xml
+// declaration, comments, and directives are dropped.  Documents that differ only in these respects
+// have the same canonical form.
+func Canonicalize(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &node{}
+	stack := []*node{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			start := xml.StartElement{Name: local(t.Name)}
+			for _, a := range t.Attr {
+				start.Attr = append(start.Attr, xml.Attr{Name: local(a.Name), Value: a.Value})
+			}
+			sort.Slice(start.Attr, func(i, j int) bool {
+				return start.Attr[i].Name.Local < start.Attr[j].Name.Local
+			})
+			child := &node{start: start, inline: parent.inline || start.Name.Local == "SpeakSentence"}
+			parent.children = append(parent.children, child)
+			stack = append(stack, child)
+		case xml.EndElement:
+			parent.trim()
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			text := string(t)
+			parent.children = append(parent.children, &node{text: text, isText: true, space: strings.TrimSpace(text) == ""})
+		}
+	}
+
+	root.trim()
+	buf := bytes.NewBuffer(nil)
+	for _, child := range root.children {
+		if err := child.write(buf, 0); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// node is an element, or text, of a canonical document
+type node struct {
+	start    xml.StartElement
+	text     string
+	isText   bool
+	space    bool // space is true for text that is only whitespace
+	inline   bool // inline is true for a <SpeakSentence> and the SSML elements within it
+	children []*node
+}
+
+// hasText returns true if the element directly contains text
+func (n *node) hasText() bool {
+	for _, child := range n.children {
+		if child.isText {
+			return true
+		}
+	}
+	return false
+}
+
+// trim removes whitespace-only text unless the element holds mixed content, and whitespace from
+// text next to the start and end tags of the element
+func (n *node) trim() {
+	mixed := n.inline
+	for _, child := range n.children {
+		mixed = mixed || (child.isText && !child.space)
+	}
+
+	var children []*node
+	for _, child := range n.children {
+		if child.space && !mixed {
+			continue
+		}
+		children = append(children, child)
+	}
+
+	if len(children) > 0 {
+		if first := children[0]; first.isText {
+			first.text = strings.TrimLeftFunc(first.text, unicode.IsSpace)
+		}
+		if last := children[len(children)-1]; last.isText {
+			last.text = strings.TrimRightFunc(last.text, unicode.IsSpace)
+		}
+	}
+
+	n.children = n.children[:0]
+	for _, child := range children {
+		if child.isText && child.text == "" {
+			continue
+		}
+		n.children = append(n.children, child)
+	}
+}
+
+// write writes the node on its own line, indenting child elements unless the node contains text
+func (n *node) write(buf *bytes.Buffer, depth int) error {
+	buf.WriteString(strings.Repeat("  ", depth))
+	if n.isText || n.hasText() {
+		encoder := xml.NewEncoder(buf)
+		if err := n.encode(encoder); err != nil {
+			return err
+		}
+		if err := encoder.Flush(); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+		return nil
+	}
+
+	encoder := xml.NewEncoder(buf)
+	if err := encoder.EncodeToken(n.start); err != nil {
+		return err
+	}
+	if len(n.children) > 0 {
+		if err := encoder.Flush(); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+		for _, child := range n.children {
+			if err := child.write(buf, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(strings.Repeat("  ", depth))
+	}
+	if err := encoder.EncodeToken(n.start.End()); err != nil {
+		return err
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+// encode writes the node and its children without indentation
+func (n *node) encode(encoder *xml.Encoder) error {
+	if n.isText {
+		return encoder.EncodeToken(xml.CharData(n.text))
+	}
+	if err := encoder.EncodeToken(n.start); err != nil {
+		return err
+	}
+	for _, child := range n.children {
+		if err := child.encode(encoder); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(n.start.End())
+}
+
+// local restores the prefix of names such as xml:lang so they are written as they were read
+func local(name xml.Name) xml.Name {
+	switch name.Space {
+	case "":
+		return name
+	case "http://www.w3.org/XML/1998/namespace":
+		return xml.Name{Local: "xml:" + name.Local}
+	default:
+		return xml.Name{Local: name.Space + ":" + name.Local}
+	}
+}
+
+// Equal fails the test unless got and want are the same document once canonicalized
+func Equal(t testing.TB, got, want []byte) {
+	t.Helper()
+
+	g, err := Canonicalize(got)
+	if err != nil {
+		t.Fatalf("unable to canonicalize got: %v", err)
+	}
+	w, err := Canonicalize(want)
+	if err != nil {
+		t.Fatalf("unable to canonicalize want: %v", err)
+	}
+	if !bytes.Equal(g, w) {
+		t.Fatalf("got\n%s\nwant\n%s", g, w)
+	}
+}
+
+// Golden compares got with the golden file testdata/<name>.  With -update-golden the file is
+// written with the canonical form of got instead.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if Update {
+		data, err := Canonicalize(got)
+		if err != nil {
+			t.Fatalf("unable to canonicalize %v: %v", name, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unable to update golden file, %v: %v", path, err)
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("unable to update golden file, %v: %v", path, err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read golden file, %v: %v; run with -update-golden to create it", path, err)
+	}
+	Equal(t, got, want)
+}
+
+// Post returns a callback request that posts event as json to target
+func Post(target string, event interface{}) *http.Request {
+	data, err := json.Marshal(event)
+	if err != nil {
+		panic(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// Document is a parsed BXML response that assertions may be made against
+type Document struct {
+	t     testing.TB
+	Body  []byte
+	Verbs []bxml.Verb
+}
+
+// Serve sends req to h and parses the BXML returned.  The test fails unless h responds with 200.
+func Serve(t testing.TB, h http.Handler, req *http.Request) *Document {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v; want %v", w.Code, http.StatusOK)
+	}
+	return Parse(t, w.Body.Bytes())
+}
+
+// Parse parses a BXML document; the test fails if it can't be parsed
+func Parse(t testing.TB, data []byte) *Document {
+	t.Helper()
+
+	verbs, err := bxml.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unable to parse BXML: %v\n%s", err, data)
+	}
+	return &Document{t: t, Body: data, Verbs: verbs}
+}
+
+// Names returns the element name of each top level verb e.g. [SpeakSentence Gather Hangup]
+func (d *Document) Names() []string {
+	names := make([]string, 0, len(d.Verbs))
+	for _, v := range d.Verbs {
+		names = append(names, name(v))
+	}
+	return names
+}
+
+// Valid fails the test unless the document passes bxml.Validate
+func (d *Document) Valid() *Document {
+	d.t.Helper()
+
+	if err := bxml.Validate(d.Verbs...); err != nil {
+		d.t.Fatalf("got %v; want valid BXML", err)
+	}
+	return d
+}
+
+// LastVerbIs fails the test unless the last verb has the given element name e.g. Hangup
+func (d *Document) LastVerbIs(verb string) *Document {
+	d.t.Helper()
+
+	if len(d.Verbs) == 0 {
+		d.t.Fatalf("got empty response; want last verb %v", verb)
+	}
+	if got := name(d.Verbs[len(d.Verbs)-1]); got != verb {
+		d.t.Fatalf("got last verb %v; want %v", got, verb)
+	}
+	return d
+}
+
+// Says fails the test unless a <SpeakSentence>, at the top level or as a <Gather> prompt, contains
+// text
+func (d *Document) Says(text string) *Document {
+	d.t.Helper()
+
+	for _, v := range d.Verbs {
+		if says(v, text) {
+			return d
+		}
+		if g, ok := v.(bxml.Gather); ok && promptsSay(g, text) {
+			return d
+		}
+	}
+	d.t.Fatalf("got %v; want a SpeakSentence that says %q", d.Names(), text)
+	return d
+}
+
+// GatherMatch describes a <Gather>; zero values match anything
+type GatherMatch struct {
+	MaxDigits         int
+	GatherUrl         string
+	TerminatingDigits string
+	Says              string // Says - text contained in one of the SpeakSentence prompts
+	Plays             string // Plays - audio uri of one of the PlayAudio prompts
+}
+
+func (m GatherMatch) matches(g bxml.Gather) bool {
+	if m.MaxDigits != 0 && g.MaxDigits != m.MaxDigits {
+		return false
+	}
+	if m.GatherUrl != "" && g.GatherUrl != m.GatherUrl {
+		return false
+	}
+	if m.TerminatingDigits != "" && g.TerminatingDigits != m.TerminatingDigits {
+		return false
+	}
+	if m.Says != "" && !promptsSay(g, m.Says) {
+		return false
+	}
+	if m.Plays != "" {
+		for _, p := range g.Prompts {
+			if audio, ok := p.(bxml.PlayAudio); ok && audio.AudioUri == m.Plays {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ContainsGather fails the test unless a top level <Gather> matches m; returns the match
+func (d *Document) ContainsGather(m GatherMatch) bxml.Gather {
+	d.t.Helper()
+
+	var gathers []bxml.Gather
+	for _, v := range d.Verbs {
+		if g, ok := v.(bxml.Gather); ok {
+			if m.matches(g) {
+				return g
+			}
+			gathers = append(gathers, g)
+		}
+	}
+	d.t.Fatalf("got gathers %+v; want %+v", gathers, m)
+	return bxml.Gather{}
+}
+
+func promptsSay(g bxml.Gather, text string) bool {
+	for _, p := range g.Prompts {
+		if says(p, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func says(v bxml.Verb, text string) bool {
+	s, ok := v.(bxml.SpeakSentence)
+	return ok && strings.Contains(s.Text(), text)
+}
+
+func name(v bxml.Verb) string {
+	s := fmt.Sprintf("%T", v)
+	return s[strings.LastIndex(s, ".")+1:]
+}
@@ -0,0 +1,114 @@
+package bxmltest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+var menu = bxml.NewResponse().
+	Speak("Welcome").
+	Gather(bxml.Gather{MaxDigits: 4, TerminatingDigits: "#", GatherUrl: "/pin"}, func(g *bxml.GatherBuilder) {
+		g.Speak("Enter your pin followed by pound").Play("https://example.com/beep.wav")
+	}).
+	Hangup()
+
+func TestCanonicalize(t *testing.T) {
+	a := []byte(`<?xml version="1.0" encoding="UTF-8"?><Response><Gather maxDigits="4" gatherUrl="/pin"><SpeakSentence> Hello </SpeakSentence></Gather></Response>`)
+	b := []byte(`<Response>
+  <!-- attribute order and whitespace don't matter -->
+  <Gather gatherUrl="/pin" maxDigits="4">
+    <SpeakSentence>Hello</SpeakSentence>
+  </Gather>
+</Response>`)
+
+	Equal(t, a, b)
+
+	got, err := Canonicalize(a)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := `<Response>
+  <Gather gatherUrl="/pin" maxDigits="4">
+    <SpeakSentence>Hello</SpeakSentence>
+  </Gather>
+</Response>
+`
+	if string(got) != want {
+		t.Fatalf("got %v; want %v", string(got), want)
+	}
+}
+
+func TestCanonicalizeSSML(t *testing.T) {
+	got, err := Canonicalize([]byte(`<SpeakSentence>Hola <lang xml:lang="es-MX">amigo</lang></SpeakSentence>`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if want := `<lang xml:lang="es-MX">`; !strings.Contains(string(got), want) {
+		t.Fatalf("got %v; want %v", string(got), want)
+	}
+}
+
+func TestCanonicalizeInlineSpace(t *testing.T) {
+	spaced, err := Canonicalize([]byte(`<Response><SpeakSentence>Hello <say-as interpret-as="cardinal">12</say-as> <emphasis>now</emphasis></SpeakSentence></Response>`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	unspaced, err := Canonicalize([]byte(`<Response><SpeakSentence>Hello <say-as interpret-as="cardinal">12</say-as><emphasis>now</emphasis></SpeakSentence></Response>`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if bytes.Equal(spaced, unspaced) {
+		t.Fatalf("got %v; want spacing between inline elements preserved", string(unspaced))
+	}
+
+	// whitespace between SSML elements matters even without other text
+	a, _ := Canonicalize([]byte(`<SpeakSentence><say-as>12</say-as> <emphasis>now</emphasis></SpeakSentence>`))
+	b, _ := Canonicalize([]byte(`<SpeakSentence><say-as>12</say-as><emphasis>now</emphasis></SpeakSentence>`))
+	if bytes.Equal(a, b) {
+		t.Fatalf("got %v; want spacing between inline elements preserved", string(b))
+	}
+}
+
+func TestCanonicalizeMixedContent(t *testing.T) {
+	spaced, err := Canonicalize([]byte(`<Response><SpeakSentence> Hola <lang xml:lang="es-MX">amigo</lang> </SpeakSentence></Response>`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := `<Response>
+  <SpeakSentence>Hola <lang xml:lang="es-MX">amigo</lang></SpeakSentence>
+</Response>
+`
+	if got := string(spaced); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	unspaced, err := Canonicalize([]byte(`<Response><SpeakSentence>Hola<lang xml:lang="es-MX">amigo</lang></SpeakSentence></Response>`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if bytes.Equal(spaced, unspaced) {
+		t.Fatalf("got %v; want spacing preserved", string(unspaced))
+	}
+}
+
+func TestGolden(t *testing.T) {
+	doc := Serve(t, menu, Post("/answer", map[string]string{"eventType": "answer"}))
+	Golden(t, "menu.golden", doc.Body)
+}
+
+func TestDocument(t *testing.T) {
+	doc := Serve(t, menu, Post("/answer", map[string]string{"eventType": "answer"}))
+
+	doc.Valid().Says("Welcome").Says("Enter your pin").LastVerbIs("Hangup")
+
+	g := doc.ContainsGather(GatherMatch{MaxDigits: 4, Says: "pin", Plays: "https://example.com/beep.wav"})
+	if got, want := g.GatherUrl, "/pin"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(doc.Names()), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
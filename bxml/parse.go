@@ -0,0 +1,400 @@
+package bxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// verbTypes maps each verb element name to the type it decodes into
+var verbTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []Verb{
+		Bridge{},
+		Conference{},
+		Forward{},
+		Gather{},
+		Hangup{},
+		Pause{},
+		PauseRecording{},
+		PlayAudio{},
+		Record{},
+		Redirect{},
+		ResumeRecording{},
+		Ring{},
+		SendDtmf{},
+		SpeakSentence{},
+		StartGather{},
+		StartRecording{},
+		StartStream{},
+		StartTranscription{},
+		StopGather{},
+		StopRecording{},
+		StopStream{},
+		StopTranscription{},
+		Tag{},
+		Transfer{},
+	} {
+		t := reflect.TypeOf(v)
+		verbTypes[t.Name()] = t
+	}
+}
+
+var verbsType = reflect.TypeOf(Verbs{})
+
+// ParseError describes a single problem found while parsing a document
+type ParseError struct {
+	Path    string // Path - location of the problem e.g. Response/Gather[0]/SpeakSentence[1]
+	Message string
+}
+
+func (p ParseError) Error() string {
+	return p.Path + ": " + p.Message
+}
+
+// ParseErrors contains every unknown verb, attribute, or invalid value found by Parse
+type ParseErrors []ParseError
+
+func (pp ParseErrors) Error() string {
+	messages := make([]string, 0, len(pp))
+	for _, p := range pp {
+		messages = append(messages, p.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Parse reads a <Response> document and returns its verbs in order.  Nested content such as
+// <Gather> prompts and <Transfer> phone numbers is decoded into the parent verb.
+//
+// Malformed xml returns a nil slice and an error.  Unknown verbs, attributes, or values that
+// can't be decoded are skipped and reported together as ParseErrors alongside the verbs that
+// could be parsed.
+func Parse(r io.Reader) ([]Verb, error) {
+	p := parser{decoder: xml.NewDecoder(r)}
+
+	if err := p.root(); err != nil {
+		return nil, err
+	}
+
+	verbs, err := p.verbs("Response")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.errs) > 0 {
+		return verbs, p.errs
+	}
+	return verbs, nil
+}
+
+type parser struct {
+	decoder *xml.Decoder
+	errs    ParseErrors
+}
+
+func (p *parser) report(path, format string, args ...interface{}) {
+	p.errs = append(p.errs, ParseError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// root consumes tokens up to and including the <Response> start element
+func (p *parser) root() error {
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return fmt.Errorf("unable to find Response element: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "Response" {
+				return fmt.Errorf("unable to parse document: want Response element; got %v", start.Name.Local)
+			}
+			for _, attr := range start.Attr {
+				p.report("Response", "unknown attribute, %v", attr.Name.Local)
+			}
+			return nil
+		}
+	}
+}
+
+// verbs decodes verbs until the end of the enclosing element
+func (p *parser) verbs(path string) ([]Verb, error) {
+	var (
+		verbs []Verb
+		index int
+	)
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %v: %w", path, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			childPath := fmt.Sprintf("%v/%v[%v]", path, t.Name.Local, index)
+			index++
+
+			typ, ok := verbTypes[t.Name.Local]
+			if !ok {
+				p.report(childPath, "unknown verb, %v", t.Name.Local)
+				if err := p.decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("unable to parse %v: %w", childPath, err)
+				}
+				continue
+			}
+
+			v := reflect.New(typ).Elem()
+			if err := p.element(t, v, childPath); err != nil {
+				return nil, err
+			}
+			verbs = append(verbs, v.Interface().(Verb))
+
+		case xml.CharData:
+			if len(strings.TrimSpace(string(t))) > 0 {
+				p.report(path, "unexpected text, %q", strings.TrimSpace(string(t)))
+			}
+
+		case xml.EndElement:
+			return verbs, nil
+		}
+	}
+}
+
+// element decodes the attributes and content of start into v
+func (p *parser) element(start xml.StartElement, v reflect.Value, path string) error {
+	fields := fieldsOf(v.Type())
+
+	for _, attr := range start.Attr {
+		i, ok := fields.attrs[attr.Name.Local]
+		if !ok {
+			p.report(path, "unknown attribute, %v", attr.Name.Local)
+			continue
+		}
+		if err := setValue(v.Field(i), attr.Value); err != nil {
+			p.report(path, "invalid value for attribute, %v: %v", attr.Name.Local, err)
+		}
+	}
+
+	if fields.innerxml >= 0 {
+		return p.innerXML(v, fields, path)
+	}
+
+	var (
+		text  strings.Builder
+		index int
+	)
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return fmt.Errorf("unable to parse %v: %w", path, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			childPath := fmt.Sprintf("%v/%v[%v]", path, t.Name.Local, index)
+			index++
+
+			if i, ok := fields.children[t.Name.Local]; ok {
+				field := v.Field(i)
+				child := reflect.New(field.Type().Elem()).Elem()
+				if err := p.element(t, child, childPath); err != nil {
+					return err
+				}
+				field.Set(reflect.Append(field, child))
+				continue
+			}
+
+			if fields.verbs >= 0 {
+				if typ, ok := verbTypes[t.Name.Local]; ok {
+					child := reflect.New(typ).Elem()
+					if err := p.element(t, child, childPath); err != nil {
+						return err
+					}
+					field := v.Field(fields.verbs)
+					field.Set(reflect.Append(field, child))
+					continue
+				}
+			}
+
+			p.report(childPath, "unknown element, %v", t.Name.Local)
+			if err := p.decoder.Skip(); err != nil {
+				return fmt.Errorf("unable to parse %v: %w", childPath, err)
+			}
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if fields.chardata >= 0 {
+				if err := setValue(v.Field(fields.chardata), content); err != nil {
+					p.report(path, "invalid content: %v", err)
+				}
+			} else if content != "" {
+				p.report(path, "unexpected text, %q", content)
+			}
+			return nil
+		}
+	}
+}
+
+// innerXML decodes content that may contain markup, such as the SSML of a <SpeakSentence>.  Plain
+// text is stored in the chardata field; content containing elements is re-encoded into the
+// innerxml field.
+func (p *parser) innerXML(v reflect.Value, fields fieldSet, path string) error {
+	var (
+		sb       strings.Builder
+		text     strings.Builder
+		pending  *xml.StartElement // pending start element, held until we know if it is empty
+		depth    int
+		elements bool
+	)
+
+	flush := func(empty bool) {
+		if pending == nil {
+			return
+		}
+		var attrs []xml.Attr
+		for _, a := range pending.Attr {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: qualifiedName(a.Name)}, Value: a.Value})
+		}
+		writeStart(&sb, qualifiedName(pending.Name), attrs)
+		if empty {
+			sb.WriteString("/>")
+		} else {
+			sb.WriteString(">")
+		}
+		pending = nil
+	}
+
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return fmt.Errorf("unable to parse %v: %w", path, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			flush(false)
+			start := t.Copy()
+			pending = &start
+			elements = true
+			depth++
+
+		case xml.CharData:
+			flush(false)
+			escape(&sb, string(t))
+			text.Write(t)
+
+		case xml.EndElement:
+			if depth == 0 {
+				if elements {
+					v.Field(fields.innerxml).SetString(strings.TrimSpace(sb.String()))
+				} else if fields.chardata >= 0 {
+					v.Field(fields.chardata).SetString(strings.TrimSpace(text.String()))
+				}
+				return nil
+			}
+			if pending != nil {
+				flush(true)
+			} else {
+				sb.WriteString("</" + qualifiedName(t.Name) + ">")
+			}
+			depth--
+		}
+	}
+}
+
+// qualifiedName restores the prefix of names such as xml:lang
+func qualifiedName(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case "http://www.w3.org/XML/1998/namespace":
+		return "xml:" + name.Local
+	default:
+		return name.Space + ":" + name.Local
+	}
+}
+
+type fieldSet struct {
+	attrs    map[string]int // attrs maps attribute name to field index
+	children map[string]int // children maps element name to the index of a slice field
+	chardata int            // chardata is the index of the chardata field or -1
+	innerxml int            // innerxml is the index of the innerxml field or -1
+	verbs    int            // verbs is the index of the nested Verbs field or -1
+}
+
+func fieldsOf(t reflect.Type) fieldSet {
+	fields := fieldSet{
+		attrs:    map[string]int{},
+		children: map[string]int{},
+		chardata: -1,
+		innerxml: -1,
+		verbs:    -1,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+
+		segments := strings.Split(tag, ",")
+		name, options := segments[0], segments[1:]
+		switch {
+		case field.Type == verbsType:
+			fields.verbs = i
+		case hasOption(options, "attr"):
+			fields.attrs[name] = i
+		case hasOption(options, "chardata"):
+			fields.chardata = i
+		case hasOption(options, "innerxml"):
+			fields.innerxml = i
+		case field.Type.Kind() == reflect.Slice && name != "":
+			fields.children[name] = i
+		}
+	}
+
+	return fields
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func setValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("want integer; got %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("want boolean; got %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("unsupported field type, %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package bxml
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	want := []Verb{
+		Gather{
+			MaxDigits: 1,
+			GatherUrl: "/menu",
+			Prompts: Verbs{
+				SpeakSentence{Voice: "julie", Sentence: "Press 1 for sales & support."},
+				PlayAudio{AudioUri: "https://example.com/hold.wav"},
+			},
+		},
+		Transfer{
+			TransferCallerId: "+18005551212",
+			PhoneNumbers:     []PhoneNumber{{Tag: "sales", Number: "+18885551212"}},
+			SipUris:          []SipUri{{Uui: "abc;encoding=base64", Uri: "sip:sales@example.com"}},
+		},
+		Record{Transcribe: true, MaxDuration: 30},
+		Hangup{},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := NewResponse(want...).WriteTo(buf); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	got, err := Parse(buf)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+}
+
+func TestParseUnknown(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<Response>
+    <SpeakSentence voice="julie">
+        Hello
+    </SpeakSentence>
+    <Dial number="+15555555555"/>
+    <Gather maxDigitz="4" maxDigits="four">
+        <Hangup/>
+        <Beep/>
+    </Gather>
+</Response>`
+
+	verbs, err := Parse(strings.NewReader(doc))
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatalf("got %T; want ParseErrors", err)
+	}
+
+	wantVerbs := []Verb{
+		SpeakSentence{Voice: "julie", Sentence: "Hello"},
+		Gather{Prompts: Verbs{Hangup{}}},
+	}
+	if !reflect.DeepEqual(verbs, wantVerbs) {
+		t.Fatalf("got %#v; want %#v", verbs, wantVerbs)
+	}
+
+	wantPaths := []string{
+		"Response/Dial[1]",
+		"Response/Gather[2]",
+		"Response/Gather[2]",
+		"Response/Gather[2]/Beep[1]",
+	}
+	if got, want := len(errs), len(wantPaths); got != want {
+		t.Fatalf("got %v; want %v: %v", got, want, errs)
+	}
+	for i, want := range wantPaths {
+		if got := errs[i].Path; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	testCases := map[string]string{
+		"not response": `<Document><Hangup/></Document>`,
+		"unclosed":     `<Response><Hangup>`,
+		"empty":        ``,
+	}
+
+	for label, doc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			verbs, err := Parse(strings.NewReader(doc))
+			if err == nil {
+				t.Fatalf("got nil; want err")
+			}
+			if verbs != nil {
+				t.Fatalf("got %v; want nil", verbs)
+			}
+		})
+	}
+}
+
+func TestParseStreaming(t *testing.T) {
+	want := []Verb{
+		Tag{Value: "support"},
+		StartGather{DtmfUrl: "/dtmf", Tag: "gather"},
+		StartStream{
+			Name:         "analysis",
+			Tracks:       "both",
+			Destination:  "wss://example.com/stream",
+			StreamParams: []StreamParam{{Name: "customer", Value: "42"}},
+		},
+		StartTranscription{
+			Name:             "live",
+			Stabilized:       Bool(true),
+			CustomParameters: []CustomParameter{{Name: "agent", Value: "7"}},
+		},
+		Pause{Duration: 30},
+		StartTranscription{Name: "raw", Stabilized: Bool(false)},
+		StopTranscription{Name: "live"},
+		StopStream{Name: "analysis"},
+		StopGather{},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := NewResponse(want...).WriteTo(buf); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	got, err := Parse(buf)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	if err := Validate(got...); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
@@ -0,0 +1,224 @@
+package bxml
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ContentType is the content type of a BXML document
+const ContentType = "application/xml"
+
+// Response is a BXML document built by chaining verbs e.g.
+//
+//	bxml.NewResponse().
+//		Speak("Welcome").
+//		Gather(bxml.Gather{MaxDigits: 1, GatherUrl: "/menu"}, func(g *bxml.GatherBuilder) {
+//			g.Speak("Press 1 for sales")
+//		}).
+//		Hangup()
+//
+// Response is a value; each method returns a new Response and leaves the receiver unchanged, so a
+// partially built Response may be safely shared and extended.  The zero value is an empty document.
+type Response struct {
+	verbs []Verb
+}
+
+// NewResponse returns a Response containing the given verbs
+func NewResponse(verbs ...Verb) Response {
+	return Response{}.Append(verbs...)
+}
+
+// Append returns a new Response with the verbs added
+func (r Response) Append(verbs ...Verb) Response {
+	// the three index slice forces append to copy rather than share the underlying array
+	r.verbs = append(r.verbs[:len(r.verbs):len(r.verbs)], verbs...)
+	return r
+}
+
+// Verbs returns the verbs of the document in order
+func (r Response) Verbs() []Verb {
+	return append([]Verb(nil), r.verbs...)
+}
+
+// Len returns the number of verbs in the document
+func (r Response) Len() int {
+	return len(r.verbs)
+}
+
+// Speak adds a <SpeakSentence>
+func (r Response) Speak(sentence string) Response {
+	return r.Append(SpeakSentence{Sentence: sentence})
+}
+
+// Play adds a <PlayAudio>
+func (r Response) Play(audioUri string) Response {
+	return r.Append(PlayAudio{AudioUri: audioUri})
+}
+
+// Pause adds a <Pause> of the given number of seconds
+func (r Response) Pause(seconds int) Response {
+	return r.Append(Pause{Duration: seconds})
+}
+
+// Ring adds a <Ring> of the given number of seconds
+func (r Response) Ring(seconds int) Response {
+	return r.Append(Ring{Duration: seconds})
+}
+
+// SendDtmf adds a <SendDtmf>
+func (r Response) SendDtmf(digits string) Response {
+	return r.Append(SendDtmf{Digits: digits})
+}
+
+// Gather adds a <Gather> using the attributes of opts.  fn, if not nil, adds the prompts played
+// while digits are collected.
+func (r Response) Gather(opts Gather, fn func(g *GatherBuilder)) Response {
+	builder := GatherBuilder{prompts: append(Verbs(nil), opts.Prompts...)}
+	if fn != nil {
+		fn(&builder)
+	}
+	opts.Prompts = builder.prompts
+	return r.Append(opts)
+}
+
+// Transfer adds a <Transfer> using the attributes of opts to each of the targets.  Targets beginning
+// with sip: are added as <SipUri>, all others as <PhoneNumber>.
+func (r Response) Transfer(opts Transfer, targets ...string) Response {
+	opts.PhoneNumbers = append([]PhoneNumber(nil), opts.PhoneNumbers...)
+	opts.SipUris = append([]SipUri(nil), opts.SipUris...)
+	for _, target := range targets {
+		if strings.HasPrefix(target, "sip:") {
+			opts.SipUris = append(opts.SipUris, SipUri{Uri: target})
+		} else {
+			opts.PhoneNumbers = append(opts.PhoneNumbers, PhoneNumber{Number: target})
+		}
+	}
+	return r.Append(opts)
+}
+
+// Forward adds a <Forward> to the given number
+func (r Response) Forward(to string) Response {
+	return r.Append(Forward{To: to})
+}
+
+// Conference adds a <Conference> joining the named conference
+func (r Response) Conference(name string) Response {
+	return r.Append(Conference{Name: name})
+}
+
+// Bridge adds a <Bridge> to the target call
+func (r Response) Bridge(targetCall string) Response {
+	return r.Append(Bridge{TargetCall: targetCall})
+}
+
+// StartStream adds a <StartStream> using the attributes of opts sending audio to destination
+func (r Response) StartStream(opts StartStream, destination string) Response {
+	opts.Destination = destination
+	return r.Append(opts)
+}
+
+// StopStream adds a <StopStream> for the named stream
+func (r Response) StopStream(name string) Response {
+	return r.Append(StopStream{Name: name})
+}
+
+// Tag adds a <Tag> that sets the tag of all future callbacks; an empty value clears the tag
+func (r Response) Tag(value string) Response {
+	return r.Append(Tag{Value: value})
+}
+
+// Record adds a <Record>
+func (r Response) Record(opts Record) Response {
+	return r.Append(opts)
+}
+
+// Redirect adds a <Redirect> to the given url
+func (r Response) Redirect(redirectUrl string) Response {
+	return r.Append(Redirect{RedirectUrl: redirectUrl})
+}
+
+// Hangup adds a <Hangup>
+func (r Response) Hangup() Response {
+	return r.Append(Hangup{})
+}
+
+// Validate checks the document against the BXML rules; see Validate
+func (r Response) Validate() error {
+	return Validate(r.verbs...)
+}
+
+// WriteTo writes the document to w
+func (r Response) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := writeVerbs(cw, r.verbs)
+	return cw.n, err
+}
+
+// Bytes returns the encoded document
+func (r Response) Bytes() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := writeVerbs(buf, r.verbs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ServeHTTP writes the document as the http response
+func (r Response) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, err := r.Bytes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Write(data)
+}
+
+// GatherBuilder adds the prompts of a <Gather>
+type GatherBuilder struct {
+	prompts Verbs
+}
+
+// Speak adds a <SpeakSentence> prompt
+func (g *GatherBuilder) Speak(sentence string) *GatherBuilder {
+	return g.SpeakSentence(SpeakSentence{Sentence: sentence})
+}
+
+// SpeakSentence adds a <SpeakSentence> prompt with the voice, gender, and locale of v
+func (g *GatherBuilder) SpeakSentence(v SpeakSentence) *GatherBuilder {
+	g.prompts = append(g.prompts, v)
+	return g
+}
+
+// Play adds a <PlayAudio> prompt
+func (g *GatherBuilder) Play(audioUri string) *GatherBuilder {
+	return g.PlayAudio(PlayAudio{AudioUri: audioUri})
+}
+
+// PlayAudio adds a <PlayAudio> prompt with the credentials of v
+func (g *GatherBuilder) PlayAudio(v PlayAudio) *GatherBuilder {
+	g.prompts = append(g.prompts, v)
+	return g
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// writeVerbs writes verbs as a document with Write
+func writeVerbs(w io.Writer, verbs []Verb) error {
+	vv := make([]interface{}, 0, len(verbs))
+	for _, v := range verbs {
+		vv = append(vv, v)
+	}
+	return Write(w, vv...)
+}
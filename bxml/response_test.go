@@ -0,0 +1,71 @@
+package bxml
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponse(t *testing.T) {
+	menu := NewResponse().
+		Speak("Welcome").
+		Gather(Gather{MaxDigits: 1, GatherUrl: "/menu"}, func(g *GatherBuilder) {
+			g.Speak("Press 1 for sales").Play("https://example.com/beep.wav")
+		})
+
+	// extending the same response twice must not share verbs
+	sales := menu.Transfer(Transfer{TransferCallerId: "+18005551212"}, "+18885551212", "sip:sales@example.com")
+	goodbye := menu.Speak("Goodbye").Hangup()
+
+	if got, want := menu.Len(), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if _, ok := sales.Verbs()[2].(Transfer); !ok {
+		t.Fatalf("got %T; want Transfer", sales.Verbs()[2])
+	}
+	if _, ok := goodbye.Verbs()[2].(SpeakSentence); !ok {
+		t.Fatalf("got %T; want SpeakSentence", goodbye.Verbs()[2])
+	}
+
+	data, err := sales.Bytes()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?><Response>` +
+		`<SpeakSentence>Welcome</SpeakSentence>` +
+		`<Gather gatherUrl="/menu" maxDigits="1"><SpeakSentence>Press 1 for sales</SpeakSentence><PlayAudio>https://example.com/beep.wav</PlayAudio></Gather>` +
+		`<Transfer transferCallerId="+18005551212"><PhoneNumber>+18885551212</PhoneNumber><SipUri>sip:sales@example.com</SipUri></Transfer>` +
+		`</Response>`
+	if got := string(data); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	n, err := sales.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := n, int64(len(want)); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := goodbye.Validate(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
+
+func TestResponseServeHTTP(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewResponse().Hangup().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/answer", nil))
+
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := w.Header().Get("Content-Type"), ContentType; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := w.Body.String(), `<?xml version="1.0" encoding="UTF-8"?><Response><Hangup></Hangup></Response>`; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
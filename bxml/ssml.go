@@ -0,0 +1,352 @@
+package bxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	reBreakTime    = regexp.MustCompile(`^\d+(\.\d+)?(ms|s)$`)
+	reLocale       = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+	rePercent      = regexp.MustCompile(`^\d+%$`)
+	reRelativePct  = regexp.MustCompile(`^[+-]\d+(\.\d+)?%$`)
+	reRelativeDb   = regexp.MustCompile(`^[+-]\d+(\.\d+)?dB$`)
+	breakStrengths = []string{"none", "x-weak", "weak", "medium", "strong", "x-strong"}
+	emphasisLevels = []string{"strong", "moderate", "reduced"}
+	interpretAs    = []string{"characters", "spell-out", "cardinal", "number", "ordinal", "digits", "fraction", "unit", "date", "time", "telephone", "address", "interjection", "expletive"}
+	prosodyRates   = []string{"x-slow", "slow", "medium", "fast", "x-fast"}
+	prosodyPitches = []string{"x-low", "low", "medium", "high", "x-high"}
+	prosodyVolumes = []string{"silent", "x-soft", "soft", "medium", "loud", "x-loud"}
+)
+
+// ssmlTags maps each supported SSML tag to its allowed attributes
+var ssmlTags = map[string][]string{
+	"break":    {"time", "strength"},
+	"emphasis": {"level"},
+	"lang":     {"xml:lang"},
+	"p":        nil,
+	"prosody":  {"rate", "pitch", "volume"},
+	"s":        nil,
+	"say-as":   {"interpret-as", "format"},
+	"sub":      {"alias"},
+}
+
+// ssmlNode is either text or an element with attributes and children
+type ssmlNode struct {
+	text     string
+	name     string
+	attrs    []xml.Attr
+	children []ssmlNode
+}
+
+// SSML builds the content of a <SpeakSentence> from the SSML tags Bandwidth supports; <lang>,
+// <p>, <s>, <break>, <emphasis>, <say-as>, <sub>, and <prosody>.  Text is escaped as it is
+// added and attribute values are checked as each tag is added.  Problems are reported by Render.
+//
+//	s := bxml.NewSSML().
+//		Text("Your balance is").
+//		SayAs("cardinal", "", "42").
+//		Break("500ms", "").
+//		Emphasis("strong", "thank you")
+type SSML struct {
+	nodes []ssmlNode
+	errs  []string
+}
+
+// NewSSML returns an empty SSML document
+func NewSSML() *SSML {
+	return &SSML{}
+}
+
+// Text adds plain text
+func (s *SSML) Text(text string) *SSML {
+	s.nodes = append(s.nodes, ssmlNode{text: text})
+	return s
+}
+
+// Break adds a pause of the given time (e.g. 500ms or 2s) or strength; either may be empty
+func (s *SSML) Break(time, strength string) *SSML {
+	if time != "" && !reBreakTime.MatchString(time) {
+		s.errorf("break time must be a number of ms or s; got %q", time)
+	}
+	s.checkOneOf("break strength", strength, breakStrengths)
+	s.nodes = append(s.nodes, element("break", attrs("time", time, "strength", strength)))
+	return s
+}
+
+// Emphasis adds text spoken with the given level of emphasis; strong, moderate, or reduced
+func (s *SSML) Emphasis(level, text string) *SSML {
+	s.checkOneOf("emphasis level", level, emphasisLevels)
+	s.nodes = append(s.nodes, element("emphasis", attrs("level", level), ssmlNode{text: text}))
+	return s
+}
+
+// SayAs adds text interpreted as the given type e.g. digits, date, or telephone.  format is
+// optional and is typically used with dates e.g. mdy
+func (s *SSML) SayAs(interpret, format, text string) *SSML {
+	if interpret == "" {
+		s.errorf("say-as requires interpret-as")
+	}
+	s.checkOneOf("say-as interpret-as", interpret, interpretAs)
+	s.nodes = append(s.nodes, element("say-as", attrs("interpret-as", interpret, "format", format), ssmlNode{text: text}))
+	return s
+}
+
+// Sub adds text that is spoken as alias e.g. Sub("aluminum", "Al")
+func (s *SSML) Sub(alias, text string) *SSML {
+	if alias == "" {
+		s.errorf("sub requires alias")
+	}
+	s.nodes = append(s.nodes, element("sub", attrs("alias", alias), ssmlNode{text: text}))
+	return s
+}
+
+// Prosody adds content spoken with the given rate, pitch, and volume; any may be empty
+func (s *SSML) Prosody(rate, pitch, volume string, fn func(s *SSML)) *SSML {
+	if rate != "" && !rePercent.MatchString(rate) {
+		s.checkOneOf("prosody rate", rate, prosodyRates)
+	}
+	if pitch != "" && !reRelativePct.MatchString(pitch) {
+		s.checkOneOf("prosody pitch", pitch, prosodyPitches)
+	}
+	if volume != "" && !reRelativeDb.MatchString(volume) {
+		s.checkOneOf("prosody volume", volume, prosodyVolumes)
+	}
+	return s.nest("prosody", attrs("rate", rate, "pitch", pitch, "volume", volume), fn)
+}
+
+// Lang adds content spoken in the given language e.g. es-MX
+func (s *SSML) Lang(locale string, fn func(s *SSML)) *SSML {
+	if !reLocale.MatchString(locale) {
+		s.errorf("lang must be a locale like en-US; got %q", locale)
+	}
+	return s.nest("lang", []xml.Attr{{Name: xml.Name{Local: "xml:lang"}, Value: locale}}, fn)
+}
+
+// Paragraph adds a <p>
+func (s *SSML) Paragraph(fn func(s *SSML)) *SSML {
+	return s.nest("p", nil, fn)
+}
+
+// Sentence adds an <s>
+func (s *SSML) Sentence(fn func(s *SSML)) *SSML {
+	return s.nest("s", nil, fn)
+}
+
+// Render returns the encoded SSML or an error describing every unsupported tag value
+func (s *SSML) Render() (string, error) {
+	if len(s.errs) > 0 {
+		return "", fmt.Errorf("invalid ssml: %v", strings.Join(s.errs, "; "))
+	}
+
+	var sb strings.Builder
+	for _, n := range s.nodes {
+		n.render(&sb)
+	}
+	return sb.String(), nil
+}
+
+// PlainText returns the text that would be spoken, without markup, for use in tests and logs.
+// Substitutions are rendered as their alias and whitespace is collapsed.
+func (s *SSML) PlainText() string {
+	var sb strings.Builder
+	for _, n := range s.nodes {
+		n.plainText(&sb)
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// SpeakSentence returns a <SpeakSentence> that speaks the SSML using the given voice
+func (s *SSML) SpeakSentence(voice string) (SpeakSentence, error) {
+	content, err := s.Render()
+	if err != nil {
+		return SpeakSentence{}, err
+	}
+	return SpeakSentence{Voice: voice, SSML: content}, nil
+}
+
+func (s *SSML) nest(name string, attrs []xml.Attr, fn func(s *SSML)) *SSML {
+	child := &SSML{}
+	if fn != nil {
+		fn(child)
+	}
+	s.errs = append(s.errs, child.errs...)
+	s.nodes = append(s.nodes, element(name, attrs, child.nodes...))
+	return s
+}
+
+func (s *SSML) errorf(format string, args ...interface{}) {
+	s.errs = append(s.errs, fmt.Sprintf(format, args...))
+}
+
+func (s *SSML) checkOneOf(name, value string, allowed []string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	s.errorf("%v must be one of %v; got %q", name, strings.Join(allowed, ", "), value)
+}
+
+func element(name string, attrs []xml.Attr, children ...ssmlNode) ssmlNode {
+	return ssmlNode{name: name, attrs: attrs, children: children}
+}
+
+// attrs returns attributes from name value pairs, omitting empty values
+func attrs(kv ...string) []xml.Attr {
+	var aa []xml.Attr
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			aa = append(aa, xml.Attr{Name: xml.Name{Local: kv[i]}, Value: kv[i+1]})
+		}
+	}
+	return aa
+}
+
+func (n ssmlNode) render(sb *strings.Builder) {
+	if n.name == "" {
+		escape(sb, n.text)
+		return
+	}
+
+	writeStart(sb, n.name, n.attrs)
+	if len(n.children) == 0 {
+		sb.WriteString("/>")
+		return
+	}
+	sb.WriteString(">")
+	for _, child := range n.children {
+		child.render(sb)
+	}
+	sb.WriteString("</" + n.name + ">")
+}
+
+func (n ssmlNode) plainText(sb *strings.Builder) {
+	if n.name == "" {
+		sb.WriteString(n.text)
+		return
+	}
+
+	// tags are treated as word boundaries
+	sb.WriteString(" ")
+	defer sb.WriteString(" ")
+
+	switch n.name {
+	case "break":
+	case "sub":
+		for _, a := range n.attrs {
+			if a.Name.Local == "alias" {
+				sb.WriteString(a.Value)
+			}
+		}
+	default:
+		for _, child := range n.children {
+			child.plainText(sb)
+		}
+	}
+}
+
+// writeStart writes a start tag without the closing > so the caller may self close it
+func writeStart(sb *strings.Builder, name string, attrs []xml.Attr) {
+	sb.WriteString("<" + name)
+	for _, a := range attrs {
+		sb.WriteString(" " + a.Name.Local + `="`)
+		escape(sb, a.Value)
+		sb.WriteString(`"`)
+	}
+}
+
+func escape(sb *strings.Builder, s string) {
+	xml.EscapeText(sb, []byte(s))
+}
+
+// checkSSML returns a description of each unsupported tag or attribute in the SSML content
+func checkSSML(content string) []string {
+	var problems []string
+
+	decoder := xml.NewDecoder(strings.NewReader("<speak>" + content + "</speak>"))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return problems
+		}
+		if err != nil {
+			return append(problems, fmt.Sprintf("invalid ssml: %v", err))
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "speak" && start.Name.Space == "" {
+			continue
+		}
+
+		name := qualifiedName(start.Name)
+		allowed, ok := ssmlTags[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unsupported ssml tag, %v", name))
+			continue
+		}
+	attrs:
+		for _, a := range start.Attr {
+			attr := qualifiedName(a.Name)
+			for _, want := range allowed {
+				if attr == want {
+					continue attrs
+				}
+			}
+			problems = append(problems, fmt.Sprintf("unsupported attribute, %v, on ssml tag, %v", attr, name))
+		}
+	}
+}
+
+// Text returns the words spoken by the sentence without markup, for use in tests and logs.
+// Substitutions are rendered as their alias and whitespace is collapsed.
+func (s SpeakSentence) Text() string {
+	if s.SSML == "" {
+		return strings.Join(strings.Fields(s.Sentence), " ")
+	}
+
+	var (
+		sb   strings.Builder
+		skip int // skip counts the depth within a <sub> whose content is replaced by its alias
+	)
+	decoder := xml.NewDecoder(strings.NewReader("<speak>" + s.SSML + "</speak>"))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			sb.WriteString(" ") // tags are treated as word boundaries
+			if skip > 0 {
+				skip++
+				continue
+			}
+			if t.Name.Local == "sub" {
+				for _, a := range t.Attr {
+					if a.Name.Local == "alias" {
+						sb.WriteString(a.Value)
+					}
+				}
+				skip = 1
+			}
+		case xml.EndElement:
+			sb.WriteString(" ")
+			if skip > 0 {
+				skip--
+			}
+		case xml.CharData:
+			if skip == 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
@@ -0,0 +1,98 @@
+package bxml
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSSML(t *testing.T) {
+	s := NewSSML().
+		Text("Tom & Jerry's balance is").
+		SayAs("cardinal", "", "42").
+		Break("500ms", "").
+		Prosody("slow", "", "loud", func(s *SSML) {
+			s.Emphasis("strong", "thank you")
+		}).
+		Lang("es-MX", func(s *SSML) {
+			s.Text("gracias")
+		}).
+		Sub("aluminum", "Al")
+
+	got, err := s.Render()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := `Tom &amp; Jerry&#39;s balance is` +
+		`<say-as interpret-as="cardinal">42</say-as>` +
+		`<break time="500ms"/>` +
+		`<prosody rate="slow" volume="loud"><emphasis level="strong">thank you</emphasis></prosody>` +
+		`<lang xml:lang="es-MX">gracias</lang>` +
+		`<sub alias="aluminum">Al</sub>`
+	if got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if got, want := s.PlainText(), "Tom & Jerry's balance is 42 thank you gracias aluminum"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	sentence, err := s.SpeakSentence("julie")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := Validate(sentence); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	// SSML survives a round trip through Write and Parse
+	buf := bytes.NewBuffer(nil)
+	if err := Write(buf, sentence); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	verbs, err := Parse(buf)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(verbs, []Verb{sentence}) {
+		t.Fatalf("got %#v; want %#v", verbs, []Verb{sentence})
+	}
+
+	// the parsed sentence speaks the same words as the builder
+	if got, want := verbs[0].(SpeakSentence).Text(), s.PlainText(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestSSMLInvalid(t *testing.T) {
+	s := NewSSML().
+		Break("soon", "").
+		Emphasis("loud", "hi").
+		Prosody("", "", "", func(s *SSML) {
+			s.SayAs("", "", "1")
+		}).
+		Lang("spanish", nil)
+
+	_, err := s.Render()
+	if err == nil {
+		t.Fatalf("got nil; want err")
+	}
+	for _, want := range []string{"break time", "emphasis level", "say-as requires interpret-as", "lang must be a locale"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("got %v; want %v", err, want)
+		}
+	}
+}
+
+func TestValidateSSML(t *testing.T) {
+	err := Validate(SpeakSentence{SSML: `<whisper>hi</whisper><break time="1s" length="2s"/>`})
+	if err == nil {
+		t.Fatalf("got nil; want err")
+	}
+	for _, want := range []string{"unsupported ssml tag, whisper", "unsupported attribute, length, on ssml tag, break"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("got %v; want %v", err, want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package bxml
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxTagLength = 256 // maxTagLength is the longest tag Bandwidth accepts
+	maxParams    = 12  // maxParams is the most <StreamParam> or <CustomParameter> a verb may contain
+)
+
+var (
+	methods             = []string{"GET", "POST"}
+	diversionReasons    = []string{"unknown", "user-busy", "no-answer", "unavailable", "unconditional", "time-of-day", "do-not-disturb", "deflection", "follow-me", "out-of-service", "away"}
+	diversionTreatments = []string{"propagate", "stack", "none"}
+	fileFormats         = []string{"mp3", "wav"}
+	genders             = []string{"male", "female"}
+	tracks              = []string{"inbound", "outbound", "both"}
+
+	// terminalVerbs end BXML execution; verbs that follow them are never executed
+	terminalVerbs = map[string]bool{
+		"Forward":  true,
+		"Hangup":   true,
+		"Redirect": true,
+	}
+)
+
+// ValidationError describes a single rule a verb breaks
+type ValidationError struct {
+	Path    string // Path - location of the verb e.g. Response/Gather[0]/SpeakSentence[1]
+	Message string
+}
+
+func (v ValidationError) Error() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidationErrors contains every problem found by Validate
+type ValidationErrors []ValidationError
+
+func (vv ValidationErrors) Error() string {
+	messages := make([]string, 0, len(vv))
+	for _, v := range vv {
+		messages = append(messages, v.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks the verbs of a document against Bandwidth's BXML rules; required attributes,
+// allowed nesting, numeric ranges, allowed values, verbs following a terminal verb, and url
+// syntax.  Returns nil if the document is valid or ValidationErrors containing every problem.
+func Validate(verbs ...Verb) error {
+	var v validator
+	var terminal string
+	for i, verb := range verbs {
+		path := fmt.Sprintf("Response/%v[%v]", elementName(verb), i)
+		if terminal != "" {
+			v.report(path, "unreachable; follows terminal verb, %v", terminal)
+		}
+		v.verb(path, verb)
+		if name := elementName(verb); terminalVerbs[name] && terminal == "" {
+			terminal = name
+		}
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) report(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) verb(path string, verb interface{}) {
+	if value := reflect.ValueOf(verb); value.Kind() == reflect.Ptr && !value.IsNil() {
+		verb = value.Elem().Interface()
+	}
+	if _, ok := verbTypes[elementName(verb)]; !ok {
+		v.report(path, "unknown verb, %T", verb)
+		return
+	}
+
+	v.attributes(path, verb)
+
+	switch t := verb.(type) {
+	case Bridge:
+		v.required(path, "target call", t.TargetCall)
+
+	case Conference:
+		v.required(path, "conference name", t.Name)
+		v.between(path, "callbackTimeout", float64(t.CallbackTimeout), 1, 25)
+
+	case Forward:
+		v.required(path, "to", t.To)
+		v.between(path, "callTimeout", float64(t.CallTimeout), 1, 300)
+		v.oneOf(path, "diversionReason", t.DiversionReason, diversionReasons)
+		v.oneOf(path, "diversionTreatment", t.DiversionTreatment, diversionTreatments)
+
+	case Gather:
+		v.between(path, "maxDigits", float64(t.MaxDigits), 1, 50)
+		v.between(path, "firstDigitTimeout", float64(t.FirstDigitTimeout), 0, 60)
+		v.between(path, "interDigitTimeout", float64(t.InterDigitTimeout), 1, 60)
+		if t.RepeatCount*len(t.Prompts) > 20 {
+			v.report(path, "repeatCount * number of prompts must not exceed 20; got %v", t.RepeatCount*len(t.Prompts))
+		}
+		for i, prompt := range t.Prompts {
+			name := elementName(prompt)
+			childPath := fmt.Sprintf("%v/%v[%v]", path, name, i)
+			if name != "SpeakSentence" && name != "PlayAudio" {
+				v.report(childPath, "Gather may only contain SpeakSentence or PlayAudio; got %v", name)
+				continue
+			}
+			v.verb(childPath, prompt)
+		}
+
+	case Pause:
+		v.between(path, "duration", float64(t.Duration), 0, 86400)
+
+	case PlayAudio:
+		v.required(path, "audio uri", t.AudioUri)
+		v.url(path, "audio uri", t.AudioUri)
+
+	case Record:
+		v.between(path, "maxDuration", float64(t.MaxDuration), 1, 10800)
+		v.between(path, "silenceTimeout", float64(t.SilenceTimeout), 0, 10800)
+		v.oneOf(path, "fileFormat", t.FileFormat, fileFormats)
+
+	case Redirect:
+		v.required(path, "redirectUrl", t.RedirectUrl)
+
+	case Ring:
+		v.between(path, "duration", float64(t.Duration), 0, 86400)
+
+	case SendDtmf:
+		v.required(path, "digits", t.Digits)
+		if strings.Trim(t.Digits, "0123456789*#wW") != "" {
+			v.report(path, "digits may only contain 0-9, *, #, w, or W; got %q", t.Digits)
+		}
+		v.numeric(path, "toneDuration", t.ToneDuration, 50, 5000)
+		v.numeric(path, "toneInterval", t.ToneInterval, 50, 5000)
+
+	case SpeakSentence:
+		v.required(path, "sentence", t.Sentence+t.SSML)
+		for _, problem := range checkSSML(t.SSML) {
+			v.report(path, "%v", problem)
+		}
+		v.oneOf(path, "gender", t.Gender, genders)
+
+	case StartGather:
+		v.required(path, "dtmfUrl", t.DtmfUrl)
+
+	case StartRecording:
+		v.oneOf(path, "fileFormat", t.FileFormat, fileFormats)
+
+	case StartStream:
+		v.required(path, "destination", t.Destination)
+		v.websocket(path, "destination", t.Destination)
+		v.oneOf(path, "tracks", t.Tracks, tracks)
+		if len(t.StreamParams) > maxParams {
+			v.report(path, "may contain at most %v StreamParam; got %v", maxParams, len(t.StreamParams))
+		}
+		for i, param := range t.StreamParams {
+			v.required(fmt.Sprintf("%v/StreamParam[%v]", path, i), "name", param.Name)
+		}
+
+	case StartTranscription:
+		v.websocket(path, "destination", t.Destination)
+		v.oneOf(path, "tracks", t.Tracks, tracks)
+		if len(t.CustomParameters) > maxParams {
+			v.report(path, "may contain at most %v CustomParameter; got %v", maxParams, len(t.CustomParameters))
+		}
+		for i, param := range t.CustomParameters {
+			v.required(fmt.Sprintf("%v/CustomParameter[%v]", path, i), "name", param.Name)
+		}
+
+	case Tag:
+		if len(t.Value) > maxTagLength {
+			v.report(path, "tag may not exceed %v characters; got %v", maxTagLength, len(t.Value))
+		}
+
+	case Transfer:
+		v.numeric(path, "callTimeout", t.CallTimeout, 1, 300)
+		v.oneOf(path, "diversionReason", t.DiversionReason, diversionReasons)
+		v.oneOf(path, "diversionTreatment", t.DiversionTreatment, diversionTreatments)
+		if len(t.PhoneNumbers)+len(t.SipUris) == 0 {
+			v.report(path, "must contain at least one PhoneNumber or SipUri")
+		}
+		for i, number := range t.PhoneNumbers {
+			childPath := fmt.Sprintf("%v/PhoneNumber[%v]", path, i)
+			v.attributes(childPath, number)
+			v.required(childPath, "number", number.Number)
+		}
+		for i, uri := range t.SipUris {
+			childPath := fmt.Sprintf("%v/SipUri[%v]", path, len(t.PhoneNumbers)+i)
+			v.attributes(childPath, uri)
+			v.required(childPath, "uri", uri.Uri)
+			if uri.Uri != "" && !strings.HasPrefix(uri.Uri, "sip:") {
+				v.report(childPath, "uri must begin with sip:; got %q", uri.Uri)
+			}
+		}
+	}
+}
+
+// attributes applies the rules common to every verb; methods, urls, and tags
+func (v *validator) attributes(path string, verb interface{}) {
+	value := reflect.ValueOf(verb)
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("xml")
+		if !strings.HasSuffix(tag, ",attr,omitempty") || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		s := value.Field(i).String()
+		switch {
+		case strings.HasSuffix(name, "Method"):
+			v.oneOf(path, name, s, methods)
+		case strings.HasSuffix(name, "Url"):
+			v.url(path, name, s)
+		case name == "tag":
+			if len(s) > maxTagLength {
+				v.report(path, "tag may not exceed %v characters; got %v", maxTagLength, len(s))
+			}
+		}
+	}
+}
+
+func (v *validator) required(path, name, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.report(path, "%v is required", name)
+	}
+}
+
+// between reports values outside [min, max]; zero values are unset and always valid
+func (v *validator) between(path, name string, value, min, max float64) {
+	if value != 0 && (value < min || value > max) {
+		v.report(path, "%v must be between %v and %v; got %v", name, min, max, value)
+	}
+}
+
+// numeric validates numbers that are held as strings
+func (v *validator) numeric(path, name, value string, min, max float64) {
+	if value == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		v.report(path, "%v must be a number; got %q", name, value)
+		return
+	}
+	v.between(path, name, f, min, max)
+}
+
+func (v *validator) oneOf(path, name, value string, allowed []string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return
+		}
+	}
+	v.report(path, "%v must be one of %v; got %q", name, strings.Join(allowed, ", "), value)
+}
+
+// url reports urls that can't be parsed; relative urls are permitted, absolute urls must be http or https
+func (v *validator) url(path, name, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		v.report(path, "%v is not a valid url: %v", name, err)
+		return
+	}
+	if u.IsAbs() && u.Scheme != "http" && u.Scheme != "https" {
+		v.report(path, "%v must be an http or https url; got %q", name, value)
+	}
+}
+
+// websocket reports destinations that are not absolute ws or wss urls
+func (v *validator) websocket(path, name, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "wss" && u.Scheme != "ws") || u.Host == "" {
+		v.report(path, "%v must be a ws or wss url; got %q", name, value)
+	}
+}
+
+func elementName(verb interface{}) string {
+	t := reflect.TypeOf(verb)
+	if t == nil {
+		return "nil"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
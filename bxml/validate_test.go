@@ -0,0 +1,111 @@
+package bxml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		err := Validate(
+			Gather{
+				MaxDigits:    4,
+				GatherUrl:    "/gather",
+				GatherMethod: "POST",
+				Prompts:      Verbs{SpeakSentence{Sentence: "Enter your pin"}},
+			},
+			&Transfer{
+				CallTimeout:  "30",
+				PhoneNumbers: []PhoneNumber{{Number: "+18885551212", TransferAnswerUrl: "https://example.com/answer"}},
+			},
+			Hangup{},
+		)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := Validate(
+			Gather{
+				MaxDigits:    51,
+				GatherMethod: "PUT",
+				Prompts: Verbs{
+					SpeakSentence{},
+					Hangup{},
+				},
+			},
+			Forward{To: "+18885551212", CallTimeout: 500, DiversionReason: "vacation"},
+			Record{MaxDuration: 20000, RecordCompleteUrl: "ftp://example.com/record"},
+			Transfer{},
+			Redirect{},
+		)
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Fatalf("got %T; want ValidationErrors", err)
+		}
+
+		want := []string{
+			"Response/Gather[0]: gatherMethod must be one of GET, POST",
+			"Response/Gather[0]: maxDigits must be between 1 and 50",
+			"Response/Gather[0]/SpeakSentence[0]: sentence is required",
+			"Response/Gather[0]/Hangup[1]: Gather may only contain SpeakSentence or PlayAudio",
+			"Response/Forward[1]: callTimeout must be between 1 and 300",
+			"Response/Forward[1]: diversionReason must be one of",
+			"Response/Record[2]: unreachable; follows terminal verb, Forward",
+			"Response/Record[2]: recordCompleteUrl must be an http or https url",
+			"Response/Record[2]: maxDuration must be between 1 and 10800",
+			"Response/Transfer[3]: unreachable; follows terminal verb, Forward",
+			"Response/Transfer[3]: must contain at least one PhoneNumber or SipUri",
+			"Response/Redirect[4]: unreachable; follows terminal verb, Forward",
+			"Response/Redirect[4]: redirectUrl is required",
+		}
+		if got, want := len(errs), len(want); got != want {
+			t.Fatalf("got %v; want %v: %v", got, want, errs)
+		}
+		for i, want := range want {
+			if got := errs[i].Error(); !strings.HasPrefix(got, want) {
+				t.Fatalf("got %v; want prefix %v", got, want)
+			}
+		}
+	})
+
+	t.Run("parsed", func(t *testing.T) {
+		doc := `<Response><Hangup/><SpeakSentence>goodbye</SpeakSentence></Response>`
+		verbs, err := Parse(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		err = Validate(verbs...)
+		if err == nil || !strings.Contains(err.Error(), "follows terminal verb, Hangup") {
+			t.Fatalf("got %v; want unreachable verb", err)
+		}
+	})
+}
+
+func TestValidateStreaming(t *testing.T) {
+	err := Validate(
+		StartGather{},
+		StartStream{Destination: "https://example.com/stream", Tracks: "sideways"},
+		Tag{Value: strings.Repeat("x", 257)},
+	)
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("got %T; want ValidationErrors", err)
+	}
+
+	want := []string{
+		"Response/StartGather[0]: dtmfUrl is required",
+		"Response/StartStream[1]: destination must be a ws or wss url",
+		"Response/StartStream[1]: tracks must be one of inbound, outbound, both",
+		"Response/Tag[2]: tag may not exceed 256 characters",
+	}
+	if got, want := len(errs), len(want); got != want {
+		t.Fatalf("got %v; want %v: %v", got, want, errs)
+	}
+	for i, want := range want {
+		if got := errs[i].Error(); !strings.HasPrefix(got, want) {
+			t.Fatalf("got %v; want prefix %v", got, want)
+		}
+	}
+}
@@ -1,17 +1,54 @@
 package bandwidth
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Event is implemented by every callback.  Accessors return an empty string, or zero time, when
+// an event type does not carry the field.
 type Event interface {
+	GetEventType() string
+	GetEventTime() time.Time
+	GetAccountId() string
+	GetApplicationId() string
+	GetCallId() string
+	GetTag() string
+}
+
+// ConferenceEvent is implemented by callbacks about a conference
+type ConferenceEvent interface {
+	Event
+	GetConferenceId() string
+	GetName() string
+}
+
+// TransferEvent is implemented by callbacks that may relate to the B leg of a <Transfer>
+type TransferEvent interface {
+	Event
+	GetParentCallId() string
+	GetTransferCallerId() string
+	GetTransferTo() string
+}
+
+// RecordingEvent is implemented by callbacks about a recording
+type RecordingEvent interface {
+	Event
+	GetRecordingId() string
+	GetMediaUrl() string
 }
 
 // AnswerEvent - https://dev.bandwidth.com/voice/bxml/callbacks/answer.html
 type AnswerEvent struct {
 	EventType     string `json:"eventType,omitempty"`     // 	The event type, value is answer
+	EventTime     string `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId     string `json:"accountId,omitempty"`     // 	The user account associated with the call.
 	ApplicationId string `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
 	To            string `json:"to,omitempty"`            // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
@@ -27,6 +64,7 @@ type AnswerEvent struct {
 // BridgeCompleteEvent - https://dev.bandwidth.com/voice/bxml/callbacks/bridgeComplete.html
 type BridgeCompleteEvent struct {
 	EventType     string `json:"eventType,omitempty"`     // 	The event type, value is bridgeComplete.
+	EventTime     string `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId     string `json:"accountId,omitempty"`     // 	The user account associated with the call.
 	ApplicationId string `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
 	From          string `json:"from,omitempty"`          // 	The phone number used in the from field of the original call, in E.164 format (e.g. +15555555555).
@@ -45,6 +83,7 @@ type BridgeCompleteEvent struct {
 // BridgeTargetCompleteEvent - https://dev.bandwidth.com/voice/bxml/callbacks/bridgeTargetComplete.html
 type BridgeTargetCompleteEvent struct {
 	EventType     string `json:"eventType,omitempty"`     // 	The event type, value is bridgeTargetComplete.
+	EventTime     string `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId     string `json:"accountId,omitempty"`     // 	The user account associated with the call.
 	ApplicationId string `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
 	From          string `json:"from,omitempty"`          // 	The phone number used in the from field of the original call, in E.164 format (e.g. +15555555555).
@@ -60,6 +99,7 @@ type BridgeTargetCompleteEvent struct {
 // ConferenceCreatedEvent - https://dev.bandwidth.com/voice/bxml/callbacks/conferenceCreated.html
 type ConferenceCreatedEvent struct {
 	EventType    string `json:"eventType,omitempty"`    // 	The event type, value is conferenceCreated.
+	EventTime    string `json:"eventTime,omitempty"`    // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	ConferenceId string `json:"conferenceId,omitempty"` // 	The ID of the new conference that was created.
 	Name         string `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
 	Tag          string `json:"tag,omitempty"`          // 	(optional) The tag that was set at conference creation, if any.
@@ -68,6 +108,7 @@ type ConferenceCreatedEvent struct {
 // ConferenceMemberJoinEvent - https://dev.bandwidth.com/voice/bxml/callbacks/conferenceMemberJoin.html
 type ConferenceMemberJoinEvent struct {
 	EventType    string `json:"eventType,omitempty"`    // 	The event type, value is conferenceMemberJoin.
+	EventTime    string `json:"eventTime,omitempty"`    // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	ConferenceId string `json:"conferenceId,omitempty"` // 	The ID of the new conference that was created.
 	Name         string `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
 	CallId       string `json:"callId,omitempty"`       // 	The callId of the member that left the conference.
@@ -79,6 +120,7 @@ type ConferenceMemberJoinEvent struct {
 // ConferenceMemberExitEvent - https://dev.bandwidth.com/voice/bxml/callbacks/conferenceMemberExit.html
 type ConferenceMemberExitEvent struct {
 	EventType    string `json:"eventType,omitempty"`    // 	The event type, value is conferenceMemberExit.
+	EventTime    string `json:"eventTime,omitempty"`    // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	ConferenceId string `json:"conferenceId,omitempty"` // 	The ID of the new conference that was created.
 	Name         string `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
 	CallId       string `json:"callId,omitempty"`       // 	The callId of the member that left the conference.
@@ -90,6 +132,7 @@ type ConferenceMemberExitEvent struct {
 // ConferenceCompletedEvent - https://dev.bandwidth.com/voice/bxml/callbacks/conferenceCompleted.html
 type ConferenceCompletedEvent struct {
 	EventType    string `json:"eventType,omitempty"`    // 	The event type, value is conferenceCompleted.
+	EventTime    string `json:"eventTime,omitempty"`    // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	ConferenceId string `json:"conferenceId,omitempty"` // 	The ID of the new conference that was created.
 	Name         string `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
 	Tag          string `json:"tag,omitempty"`          // 	(optional) The tag that was set at conference creation, if any.
@@ -98,30 +141,33 @@ type ConferenceCompletedEvent struct {
 // ConferenceRedirectEvent - https://dev.bandwidth.com/voice/bxml/callbacks/conferenceRedirect.html
 type ConferenceRedirectEvent struct {
 	EventType    string `json:"eventType,omitempty"`    // 	The event type, value is conferenceRedirect.
+	EventTime    string `json:"eventTime,omitempty"`    // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	ConferenceId string `json:"conferenceId,omitempty"` // 	The ID of the new conference that was created.
 	Name         string `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
 }
 
 // ConferenceRecordingAvailableEvent - https://dev.bandwidth.com/voice/bxml/callbacks/conferenceRecordingAvailable.html
 type ConferenceRecordingAvailableEvent struct {
-	EventType    string `json:"eventType,omitempty"`    // 	The event type, value is conferenceRecordingAvailable.
-	ConferenceId string `json:"conferenceId,omitempty"` // 	The ID of the conference that the recording was made on.
-	Name         string `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
-	AccountId    string `json:"accountId,omitempty"`    // 	The user account associated with the conference.
-	RecordingId  string `json:"recordingId,omitempty"`  // 	The unique id for this recording.
-	Channels     string `json:"channels,omitempty"`     // 	Number of channels in the recording (always 1 for conference recordings).
-	StartTime    string `json:"startTime,omitempty"`    // 	The time that the recording started (in ISO8601 format).
-	EndTime      string `json:"endTime,omitempty"`      // 	The time that the recording ended (in ISO8601 format).
-	Duration     string `json:"duration,omitempty"`     // 	The duration of the recording (in ISO8601 format).
-	FileFormat   string `json:"fileFormat,omitempty"`   // 	The audio format that the recording was saved as (wav or mp3).
-	MediaUrl     string `json:"mediaUrl,omitempty"`     // 	The URL of the recording media.
-	Tag          string `json:"tag,omitempty"`          // 	(optional) The tag that was set at conference creation, if any.
-	Status       string `json:"status,omitempty"`       // 	The state of the recording. Can be complete, partial, or error. A partial status indicates that, although the recording is available to be downloaded, parts of the recording are missing.
+	EventType    string      `json:"eventType,omitempty"`    // 	The event type, value is conferenceRecordingAvailable.
+	EventTime    string      `json:"eventTime,omitempty"`    // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	ConferenceId string      `json:"conferenceId,omitempty"` // 	The ID of the conference that the recording was made on.
+	Name         string      `json:"name,omitempty"`         // 	The custom name used to reference this conference. This the name that you included inside the body of the <Conference> tag.
+	AccountId    string      `json:"accountId,omitempty"`    // 	The user account associated with the conference.
+	RecordingId  string      `json:"recordingId,omitempty"`  // 	The unique id for this recording.
+	Channels     json.Number `json:"channels,omitempty"`     // 	Number of channels in the recording (always 1 for conference recordings).
+	StartTime    string      `json:"startTime,omitempty"`    // 	The time that the recording started (in ISO8601 format).
+	EndTime      string      `json:"endTime,omitempty"`      // 	The time that the recording ended (in ISO8601 format).
+	Duration     string      `json:"duration,omitempty"`     // 	The duration of the recording (in ISO8601 format).
+	FileFormat   string      `json:"fileFormat,omitempty"`   // 	The audio format that the recording was saved as (wav or mp3).
+	MediaUrl     string      `json:"mediaUrl,omitempty"`     // 	The URL of the recording media.
+	Tag          string      `json:"tag,omitempty"`          // 	(optional) The tag that was set at conference creation, if any.
+	Status       string      `json:"status,omitempty"`       // 	The state of the recording. Can be complete, partial, or error. A partial status indicates that, although the recording is available to be downloaded, parts of the recording are missing.
 }
 
 // DisconnectEvent - https://dev.bandwidth.com/voice/bxml/callbacks/disconnect.html
 type DisconnectEvent struct {
 	EventType     string `json:"eventType,omitempty"`     // 	The event type, value is disconnect
+	EventTime     string `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId     string `json:"accountId,omitempty"`     // 	The user account associated with the call.
 	ApplicationId string `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
 	To            string `json:"to,omitempty"`            // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
@@ -141,6 +187,7 @@ type DisconnectEvent struct {
 // https://dev.bandwidth.com/voice/bxml/callbacks/gather.html
 type GatherEvent struct {
 	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is gather.
+	EventTime        string `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
 	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
 	To               string `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
@@ -161,6 +208,7 @@ type GatherEvent struct {
 // InitiateEvent - https://dev.bandwidth.com/voice/bxml/callbacks/initiate.html
 type InitiateEvent struct {
 	EventType     string `json:"eventType,omitempty"`     // 	The event type, value is initiate.
+	EventTime     string `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId     string `json:"accountId,omitempty"`     // 	The user account associated with the call.
 	ApplicationId string `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
 	To            string `json:"to,omitempty"`            // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
@@ -174,50 +222,52 @@ type InitiateEvent struct {
 
 // RecordCompleteEvent - https://dev.bandwidth.com/voice/bxml/callbacks/recordComplete.html
 type RecordCompleteEvent struct {
-	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is recordComplete.
-	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
-	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
-	To               string `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
-	From             string `json:"from,omitempty"`             // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
-	Direction        string `json:"direction,omitempty"`        // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
-	CallId           string `json:"callId,omitempty"`           // 	The call id associated with the event.
-	ParentCallId     string `json:"parentCallId,omitempty"`     // 	(optional) If the event is related to the B leg of a <Transfer>, the call id of the original call leg that executed the <Transfer>. Otherwise, null.
-	RecordingId      string `json:"recordingId,omitempty"`      // 	The unique id for this recording.
-	CallUrl          string `json:"callUrl,omitempty"`          // 	The URL of the call associated with the event.
-	MediaUrl         string `json:"mediaUrl,omitempty"`         // 	URL to retrieve the contents of the recording.
-	AnswerTime       string `json:"answerTime,omitempty"`       // 	Time the call was answered, in ISO 8601 format.
-	StartTime        string `json:"startTime,omitempty"`        // 	Time the recording was started, in ISO 8601 format.
-	EndTime          string `json:"endTime,omitempty"`          // 	Time the recording ended, in ISO 8601 format.
-	Duration         string `json:"duration,omitempty"`         // 	Duration of the recording, in ISO 8601 format.
-	Channels         string `json:"channels,omitempty"`         // 	Number of channels in the recording.
-	FileFormat       string `json:"fileFormat,omitempty"`       // 	The audio format that the recording was saved as (wav or mp3).
-	Tag              string `json:"tag,omitempty"`              // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
-	TransferCallerId string `json:"transferCallerId,omitempty"` // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the from field of the B-leg call, in E.164 format (e.g. +15555555555). Otherwise, null.
-	TransferTo       string `json:"transferTo,omitempty"`       // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the to field of the B-leg call in E.164 format (e.g. +15555555555). Otherwise, null.
+	EventType        string      `json:"eventType,omitempty"`        // 	The event type, value is recordComplete.
+	EventTime        string      `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId        string      `json:"accountId,omitempty"`        // 	The user account associated with the call.
+	ApplicationId    string      `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
+	To               string      `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	From             string      `json:"from,omitempty"`             // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	Direction        string      `json:"direction,omitempty"`        // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId           string      `json:"callId,omitempty"`           // 	The call id associated with the event.
+	ParentCallId     string      `json:"parentCallId,omitempty"`     // 	(optional) If the event is related to the B leg of a <Transfer>, the call id of the original call leg that executed the <Transfer>. Otherwise, null.
+	RecordingId      string      `json:"recordingId,omitempty"`      // 	The unique id for this recording.
+	CallUrl          string      `json:"callUrl,omitempty"`          // 	The URL of the call associated with the event.
+	MediaUrl         string      `json:"mediaUrl,omitempty"`         // 	URL to retrieve the contents of the recording.
+	AnswerTime       string      `json:"answerTime,omitempty"`       // 	Time the call was answered, in ISO 8601 format.
+	StartTime        string      `json:"startTime,omitempty"`        // 	Time the recording was started, in ISO 8601 format.
+	EndTime          string      `json:"endTime,omitempty"`          // 	Time the recording ended, in ISO 8601 format.
+	Duration         string      `json:"duration,omitempty"`         // 	Duration of the recording, in ISO 8601 format.
+	Channels         json.Number `json:"channels,omitempty"`         // 	Number of channels in the recording.
+	FileFormat       string      `json:"fileFormat,omitempty"`       // 	The audio format that the recording was saved as (wav or mp3).
+	Tag              string      `json:"tag,omitempty"`              // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	TransferCallerId string      `json:"transferCallerId,omitempty"` // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the from field of the B-leg call, in E.164 format (e.g. +15555555555). Otherwise, null.
+	TransferTo       string      `json:"transferTo,omitempty"`       // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the to field of the B-leg call in E.164 format (e.g. +15555555555). Otherwise, null.
 }
 
 // RecordingAvailableEvent - https://dev.bandwidth.com/voice/bxml/callbacks/recordingAvailable.html
 type RecordingAvailableEvent struct {
-	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is recordingAvailable.
-	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
-	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
-	To               string `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
-	From             string `json:"from,omitempty"`             // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
-	Direction        string `json:"direction,omitempty"`        // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
-	CallId           string `json:"callId,omitempty"`           // 	The call id associated with the event.
-	ParentCallId     string `json:"parentCallId,omitempty"`     // 	(optional) If the event is related to the B leg of a <Transfer>, the call id of the original call leg that executed the <Transfer>. Otherwise, null.
-	RecordingId      string `json:"recordingId,omitempty"`      // 	The unique id for this recording.
-	Channels         string `json:"channels,omitempty"`         // 	Number of channels in the recording (1 or 2).
-	StartTime        string `json:"startTime,omitempty"`        // 	The time that the recording started (in ISO8601 format).
-	EndTime          string `json:"endTime,omitempty"`          // 	The time that the recording ended (in ISO8601 format).
-	Duration         string `json:"duration,omitempty"`         // 	The duration of the recording (in ISO8601 format).
-	FileFormat       string `json:"fileFormat,omitempty"`       // 	The audio format that the recording was saved as (wav or mp3).
-	CallUrl          string `json:"callUrl,omitempty"`          // 	The URL of the call associated with the event.
-	MediaUrl         string `json:"mediaUrl,omitempty"`         // 	The URL of the recording media.
-	Tag              string `json:"tag,omitempty"`              // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
-	Status           string `json:"status,omitempty"`           // 	The state of the recording. Can be complete, partial, or error. A partial status indicates that, although the recording is available to be downloaded, parts of the recording are missing.
-	TransferCallerId string `json:"transferCallerId,omitempty"` // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the from field of the B-leg call, in E.164 format (e.g. +15555555555). Otherwise, null.
-	TransferTo       string `json:"transferTo,omitempty"`       // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the to field of the B-leg call in E.164 format (e.g. +15555555555). Otherwise, null.
+	EventType        string      `json:"eventType,omitempty"`        // 	The event type, value is recordingAvailable.
+	EventTime        string      `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId        string      `json:"accountId,omitempty"`        // 	The user account associated with the call.
+	ApplicationId    string      `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
+	To               string      `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	From             string      `json:"from,omitempty"`             // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	Direction        string      `json:"direction,omitempty"`        // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId           string      `json:"callId,omitempty"`           // 	The call id associated with the event.
+	ParentCallId     string      `json:"parentCallId,omitempty"`     // 	(optional) If the event is related to the B leg of a <Transfer>, the call id of the original call leg that executed the <Transfer>. Otherwise, null.
+	RecordingId      string      `json:"recordingId,omitempty"`      // 	The unique id for this recording.
+	Channels         json.Number `json:"channels,omitempty"`         // 	Number of channels in the recording (1 or 2).
+	StartTime        string      `json:"startTime,omitempty"`        // 	The time that the recording started (in ISO8601 format).
+	EndTime          string      `json:"endTime,omitempty"`          // 	The time that the recording ended (in ISO8601 format).
+	Duration         string      `json:"duration,omitempty"`         // 	The duration of the recording (in ISO8601 format).
+	FileFormat       string      `json:"fileFormat,omitempty"`       // 	The audio format that the recording was saved as (wav or mp3).
+	CallUrl          string      `json:"callUrl,omitempty"`          // 	The URL of the call associated with the event.
+	MediaUrl         string      `json:"mediaUrl,omitempty"`         // 	The URL of the recording media.
+	Tag              string      `json:"tag,omitempty"`              // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	Status           string      `json:"status,omitempty"`           // 	The state of the recording. Can be complete, partial, or error. A partial status indicates that, although the recording is available to be downloaded, parts of the recording are missing.
+	TransferCallerId string      `json:"transferCallerId,omitempty"` // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the from field of the B-leg call, in E.164 format (e.g. +15555555555). Otherwise, null.
+	TransferTo       string      `json:"transferTo,omitempty"`       // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the to field of the B-leg call in E.164 format (e.g. +15555555555). Otherwise, null.
 }
 
 // TranscriptionEvent - https://dev.bandwidth.com/voice/bxml/callbacks/transcriptionAvailable.html
@@ -231,6 +281,7 @@ type TranscriptionEvent struct {
 // TranscriptionAvailableEvent - https://dev.bandwidth.com/voice/bxml/callbacks/transcriptionAvailable.html
 type TranscriptionAvailableEvent struct {
 	EventType        string             `json:"eventType,omitempty"`        // 	The event type, value is transcriptionAvailable.
+	EventTime        string             `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId        string             `json:"accountId,omitempty"`        // 	The account id associated with the event.
 	ApplicationId    string             `json:"applicationId,omitempty"`    // 	The application id associated with the event.
 	CallId           string             `json:"callId,omitempty"`           // 	The call id associated with the event.
@@ -254,6 +305,7 @@ type TranscriptionAvailableEvent struct {
 // RedirectEvent - https://dev.bandwidth.com/voice/bxml/callbacks/redirect.html
 type RedirectEvent struct {
 	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is redirect.
+	EventTime        string `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
 	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
 	To               string `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
@@ -272,6 +324,7 @@ type RedirectEvent struct {
 // TransferAnswerEvent - https://dev.bandwidth.com/voice/bxml/callbacks/transferAnswer.html
 type TransferAnswerEvent struct {
 	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is transferAnswer.
+	EventTime        string `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
 	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
 	From             string `json:"from,omitempty"`             // 	The phone number used in the from field of the original call, in E.164 format (e.g. +15555555555).
@@ -290,6 +343,7 @@ type TransferAnswerEvent struct {
 // TransferCompleteEvent - https://dev.bandwidth.com/voice/bxml/callbacks/transferComplete.html
 type TransferCompleteEvent struct {
 	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is transferComplete.
+	EventTime        string `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
 	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
 	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
 	From             string `json:"from,omitempty"`             // 	The phone number used in the from field of the original call, in E.164 format (e.g. +15555555555).
@@ -310,6 +364,9 @@ type TransferCompleteEvent struct {
 // TransferDisconnectEvent - https://dev.bandwidth.com/voice/bxml/callbacks/transferDisconnect.html
 type TransferDisconnectEvent struct {
 	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is transferDisconnect.
+	EventTime        string `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
+	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
 	From             string `json:"from,omitempty"`             // 	The phone number used in the from field of the original call, in E.164 format (e.g. +15555555555).
 	To               string `json:"to,omitempty"`               // 	The phone number user in the to field of the original call, in E.164 format (e.g. +15555555555).
 	Direction        string `json:"direction,omitempty"`        // 	The direction of the call. Always outbound for this event.
@@ -327,64 +384,317 @@ type TransferDisconnectEvent struct {
 	ErrorId          string `json:"errorId,omitempty"`          // 	Bandwidth internal id that references the error event.
 }
 
-var reEventType = regexp.MustCompile(`"eventType":\s*"([^"]+)"`)
+// DtmfEvent - https://dev.bandwidth.com/voice/bxml/callbacks/dtmf.html
+type DtmfEvent struct {
+	EventType        string `json:"eventType,omitempty"`        // 	The event type, value is dtmf.
+	EventTime        string `json:"eventTime,omitempty"`        // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId        string `json:"accountId,omitempty"`        // 	The user account associated with the call.
+	ApplicationId    string `json:"applicationId,omitempty"`    // 	The id of the application associated with the call.
+	From             string `json:"from,omitempty"`             // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	To               string `json:"to,omitempty"`               // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	Direction        string `json:"direction,omitempty"`        // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId           string `json:"callId,omitempty"`           // 	The call id associated with the event.
+	ParentCallId     string `json:"parentCallId,omitempty"`     // 	(optional) If the event is related to the B leg of a <Transfer>, the call id of the original call leg that executed the <Transfer>. Otherwise, this field will not be present.
+	CallUrl          string `json:"callUrl,omitempty"`          // 	The URL of the call associated with the event.
+	StartTime        string `json:"startTime,omitempty"`        // 	Time the call was started, in ISO 8601 format.
+	AnswerTime       string `json:"answerTime,omitempty"`       // 	Time the call was answered, in ISO 8601 format.
+	Digit            string `json:"digit,omitempty"`            // 	The digit collected in the call.
+	Tag              string `json:"tag,omitempty"`              // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	TransferCallerId string `json:"transferCallerId,omitempty"` // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the from field of the B-leg call, in E.164 format (e.g. +15555555555). Otherwise, this field will not be present.
+	TransferTo       string `json:"transferTo,omitempty"`       // 	(optional) If the event is related to the B leg of a <Transfer>, the phone number used as the to field of the B-leg call in E.164 format (e.g. +15555555555). Otherwise, this field will not be present.
+}
+
+// StreamParams describes the media stream of a stream event
+type StreamParams struct {
+	StreamId    string `json:"streamId,omitempty"`    // 	The id of the stream.
+	Name        string `json:"name,omitempty"`        // 	The user-specified name of the stream, or the stream id if no name was given.
+	Tracks      string `json:"tracks,omitempty"`      // 	The part of the call being streamed; inbound, outbound or both.
+	Destination string `json:"destination,omitempty"` // 	The destination websocket url the stream is sent to.
+}
+
+// StreamStartedEvent - https://dev.bandwidth.com/voice/bxml/callbacks/streamStarted.html
+type StreamStartedEvent struct {
+	EventType     string       `json:"eventType,omitempty"`     // 	The event type, value is streamStarted.
+	EventTime     string       `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId     string       `json:"accountId,omitempty"`     // 	The user account associated with the call.
+	ApplicationId string       `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
+	From          string       `json:"from,omitempty"`          // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	To            string       `json:"to,omitempty"`            // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	Direction     string       `json:"direction,omitempty"`     // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId        string       `json:"callId,omitempty"`        // 	The call id associated with the event.
+	CallUrl       string       `json:"callUrl,omitempty"`       // 	The URL of the call associated with the event.
+	StartTime     string       `json:"startTime,omitempty"`     // 	Time the call was started, in ISO 8601 format.
+	AnswerTime    string       `json:"answerTime,omitempty"`    // 	Time the call was answered, in ISO 8601 format.
+	Tag           string       `json:"tag,omitempty"`           // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	StreamParams  StreamParams `json:"streamParams,omitempty"`  // 	The stream that started.
+}
+
+// StreamStoppedEvent - https://dev.bandwidth.com/voice/bxml/callbacks/streamStopped.html
+type StreamStoppedEvent struct {
+	EventType     string       `json:"eventType,omitempty"`     // 	The event type, value is streamStopped.
+	EventTime     string       `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId     string       `json:"accountId,omitempty"`     // 	The user account associated with the call.
+	ApplicationId string       `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
+	From          string       `json:"from,omitempty"`          // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	To            string       `json:"to,omitempty"`            // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	Direction     string       `json:"direction,omitempty"`     // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId        string       `json:"callId,omitempty"`        // 	The call id associated with the event.
+	CallUrl       string       `json:"callUrl,omitempty"`       // 	The URL of the call associated with the event.
+	StartTime     string       `json:"startTime,omitempty"`     // 	Time the call was started, in ISO 8601 format.
+	AnswerTime    string       `json:"answerTime,omitempty"`    // 	Time the call was answered, in ISO 8601 format.
+	Tag           string       `json:"tag,omitempty"`           // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	StreamParams  StreamParams `json:"streamParams,omitempty"`  // 	The stream that stopped.
+	ErrorMessage  string       `json:"errorMessage,omitempty"`  // 	(optional) If the stream ended because of an error, text explaining the error.
+}
+
+// StreamRejectedEvent - https://dev.bandwidth.com/voice/bxml/callbacks/streamRejected.html
+type StreamRejectedEvent struct {
+	EventType     string       `json:"eventType,omitempty"`     // 	The event type, value is streamRejected.
+	EventTime     string       `json:"eventTime,omitempty"`     // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId     string       `json:"accountId,omitempty"`     // 	The user account associated with the call.
+	ApplicationId string       `json:"applicationId,omitempty"` // 	The id of the application associated with the call.
+	From          string       `json:"from,omitempty"`          // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	To            string       `json:"to,omitempty"`            // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	Direction     string       `json:"direction,omitempty"`     // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId        string       `json:"callId,omitempty"`        // 	The call id associated with the event.
+	CallUrl       string       `json:"callUrl,omitempty"`       // 	The URL of the call associated with the event.
+	StartTime     string       `json:"startTime,omitempty"`     // 	Time the call was started, in ISO 8601 format.
+	AnswerTime    string       `json:"answerTime,omitempty"`    // 	Time the call was answered, in ISO 8601 format.
+	Tag           string       `json:"tag,omitempty"`           // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	StreamParams  StreamParams `json:"streamParams,omitempty"`  // 	The stream that was rejected.
+	ErrorMessage  string       `json:"errorMessage,omitempty"`  // 	Text explaining why the stream was rejected.
+}
+
+// RealTimeTranscription is a segment of speech transcribed by <StartTranscription>
+type RealTimeTranscription struct {
+	Text       string  `json:"text,omitempty"`       // 	The transcribed text.
+	Confidence float64 `json:"confidence,omitempty"` // 	How confident the transcription engine is in the text, between 0 and 1.
+	Track      string  `json:"track,omitempty"`      // 	The part of the call that was transcribed; inbound or outbound.
+	Stable     bool    `json:"stable,omitempty"`     // 	(optional) true if the text will not change with further audio.
+}
+
+// RealTimeTranscriptionEvent - https://dev.bandwidth.com/voice/bxml/callbacks/transcription.html
+type RealTimeTranscriptionEvent struct {
+	EventType         string                `json:"eventType,omitempty"`         // 	The event type, value is transcription.
+	EventTime         string                `json:"eventTime,omitempty"`         // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId         string                `json:"accountId,omitempty"`         // 	The user account associated with the call.
+	ApplicationId     string                `json:"applicationId,omitempty"`     // 	The id of the application associated with the call.
+	From              string                `json:"from,omitempty"`              // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	To                string                `json:"to,omitempty"`                // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	Direction         string                `json:"direction,omitempty"`         // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId            string                `json:"callId,omitempty"`            // 	The call id associated with the event.
+	CallUrl           string                `json:"callUrl,omitempty"`           // 	The URL of the call associated with the event.
+	StartTime         string                `json:"startTime,omitempty"`         // 	Time the call was started, in ISO 8601 format.
+	AnswerTime        string                `json:"answerTime,omitempty"`        // 	Time the call was answered, in ISO 8601 format.
+	Tag               string                `json:"tag,omitempty"`               // 	(optional) The tag specified earlier in the call. If no tag was specified or it was previously cleared, null.
+	TranscriptionName string                `json:"transcriptionName,omitempty"` // 	The name of the real-time transcription, as set by <StartTranscription>.
+	Transcription     RealTimeTranscription `json:"transcription,omitempty"`     // 	The transcribed speech.
+}
+
+// MachineDetectionResult is the outcome of answering machine detection
+type MachineDetectionResult struct {
+	Value    string `json:"value,omitempty"`    // 	The result of the detection; human, answering-machine, silence, timeout, or error.
+	Duration string `json:"duration,omitempty"` // 	The amount of time it took to determine the result, in ISO 8601 format.
+}
+
+// MachineDetectionCompleteEvent - https://dev.bandwidth.com/docs/voice/webhooks/machineDetectionComplete
+type MachineDetectionCompleteEvent struct {
+	EventType              string                 `json:"eventType,omitempty"`              // 	The event type, value is machineDetectionComplete.
+	EventTime              string                 `json:"eventTime,omitempty"`              // 	The approximate UTC date and time when the event was generated by the Bandwidth server, in ISO 8601 format.
+	AccountId              string                 `json:"accountId,omitempty"`              // 	The user account associated with the call.
+	ApplicationId          string                 `json:"applicationId,omitempty"`          // 	The id of the application associated with the call.
+	From                   string                 `json:"from,omitempty"`                   // 	The phone number that made the call, in E.164 format (e.g. +15555555555).
+	To                     string                 `json:"to,omitempty"`                     // 	The phone number that received the call, in E.164 format (e.g. +15555555555).
+	Direction              string                 `json:"direction,omitempty"`              // 	The direction of the call. Either inbound or outbound. The direction of a call never changes.
+	CallId                 string                 `json:"callId,omitempty"`                 // 	The call id associated with the event.
+	CallUrl                string                 `json:"callUrl,omitempty"`                // 	The URL of the call associated with the event.
+	StartTime              string                 `json:"startTime,omitempty"`              // 	Time the call was started, in ISO 8601 format.
+	AnswerTime             string                 `json:"answerTime,omitempty"`             // 	Time the call was answered, in ISO 8601 format.
+	Tag                    string                 `json:"tag,omitempty"`                    // 	(optional) The tag specified on call creation. If no tag was specified or it was previously cleared, null.
+	MachineDetectionResult MachineDetectionResult `json:"machineDetectionResult,omitempty"` // 	The result of machine detection.
+}
+
+// ErrEventTooLarge is returned by DecodeEvent when a callback exceeds the size limit
+var ErrEventTooLarge = errors.New("event too large")
+
+// RawEvent is returned by ParseEvent for event types that are neither built in nor registered
+// with RegisterEvent, so new Bandwidth callbacks may be handled without failing.  The common
+// fields are decoded; Data holds the complete callback.
+type RawEvent struct {
+	EventType     string          `json:"eventType,omitempty"`
+	EventTime     string          `json:"eventTime,omitempty"`
+	AccountId     string          `json:"accountId,omitempty"`
+	ApplicationId string          `json:"applicationId,omitempty"`
+	CallId        string          `json:"callId,omitempty"`
+	Tag           string          `json:"tag,omitempty"`
+	Data          json.RawMessage `json:"-"`
+}
+
+// events maps each built in event type to its callback.  Callbacks sent to a fallback url, such
+// as answerFallbackUrl or redirectFallbackUrl, carry the same event type as those sent to the
+// primary url, and <StartGather> sends dtmf callbacks, so neither has a type of its own.
+var events = map[string]func() Event{
+	"answer":                       func() Event { return &AnswerEvent{} },
+	"bridgeComplete":               func() Event { return &BridgeCompleteEvent{} },
+	"bridgeTargetComplete":         func() Event { return &BridgeTargetCompleteEvent{} },
+	"conferenceCreated":            func() Event { return &ConferenceCreatedEvent{} },
+	"conferenceMemberJoin":         func() Event { return &ConferenceMemberJoinEvent{} },
+	"conferenceMemberExit":         func() Event { return &ConferenceMemberExitEvent{} },
+	"conferenceCompleted":          func() Event { return &ConferenceCompletedEvent{} },
+	"conferenceRedirect":           func() Event { return &ConferenceRedirectEvent{} },
+	"conferenceRecordingAvailable": func() Event { return &ConferenceRecordingAvailableEvent{} },
+	"disconnect":                   func() Event { return &DisconnectEvent{} },
+	"dtmf":                         func() Event { return &DtmfEvent{} },
+	"gather":                       func() Event { return &GatherEvent{} },
+	"initiate":                     func() Event { return &InitiateEvent{} },
+	"machineDetectionComplete":     func() Event { return &MachineDetectionCompleteEvent{} },
+	"recordComplete":               func() Event { return &RecordCompleteEvent{} },
+	"recordingAvailable":           func() Event { return &RecordingAvailableEvent{} },
+	"transcriptionAvailable":       func() Event { return &TranscriptionAvailableEvent{} },
+	"redirect":                     func() Event { return &RedirectEvent{} },
+	"streamStarted":                func() Event { return &StreamStartedEvent{} },
+	"streamStopped":                func() Event { return &StreamStoppedEvent{} },
+	"streamRejected":               func() Event { return &StreamRejectedEvent{} },
+	"transcription":                func() Event { return &RealTimeTranscriptionEvent{} },
+	"transferAnswer":               func() Event { return &TransferAnswerEvent{} },
+	"transferComplete":             func() Event { return &TransferCompleteEvent{} },
+	"transferDisconnect":           func() Event { return &TransferDisconnectEvent{} },
+}
 
+// registered maps the event types added with RegisterEvent to their callback
+var (
+	registeredMu sync.RWMutex
+	registered   = map[string]func() Event{}
+)
+
+// RegisterEvent registers the type returned by ParseEvent for callbacks with the given event
+// type.  fn must return a pointer for the callback to be unmarshaled into.  Registering an event
+// type again replaces it.  RegisterEvent panics if the event type is built in, as the typed
+// CallbackRouter helpers, e.g. OnAnswer, depend on the built in types.
+func RegisterEvent(eventType string, fn func() Event) {
+	if _, ok := events[eventType]; ok {
+		panic(fmt.Sprintf("bandwidth: unable to register event, %v: built in event types may not be replaced", eventType))
+	}
+
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	registered[eventType] = fn
+}
+
+// lookupEvent returns the callback of a built in or registered event type
+func lookupEvent(eventType string) (func() Event, bool) {
+	if fn, ok := events[eventType]; ok {
+		return fn, true
+	}
+
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	fn, ok := registered[eventType]
+	return fn, ok
+}
+
+// ParseEvent unmarshals a callback into the type registered for its event type, or a *RawEvent
+// if none is registered.  The callback is scanned only as far as its eventType field, which
+// Bandwidth sends first, and then unmarshaled once.
 func ParseEvent(data []byte) (Event, error) {
-	matches := reEventType.FindSubmatch(data)
-	if len(matches) == 0 {
-		return nil, fmt.Errorf("no event type found")
+	eventType, err := readEventType(data)
+	if err != nil {
+		return nil, err
+	}
+	return parseEvent(eventType, data, true)
+}
+
+// DecodeEvent reads a callback of at most 1MB from r and parses it as ParseEvent does.  Larger
+// callbacks return ErrEventTooLarge.
+func DecodeEvent(r io.Reader) (Event, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxCallbackSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read event: %w", err)
+	}
+	if len(data) > maxCallbackSize {
+		return nil, fmt.Errorf("unable to read event larger than %v bytes: %w", maxCallbackSize, ErrEventTooLarge)
 	}
 
-	var (
-		eventType = string(matches[1])
-		event     Event
-	)
-	switch eventType {
-	case "answer":
-		event = &AnswerEvent{}
-	case "bridgeComplete":
-		event = &BridgeCompleteEvent{}
-	case "bridgeTargetComplete":
-		event = &BridgeTargetCompleteEvent{}
-	case "conferenceCreated":
-		event = &ConferenceCreatedEvent{}
-	case "conferenceMemberJoin":
-		event = &ConferenceMemberJoinEvent{}
-	case "conferenceMemberExit":
-		event = &ConferenceMemberExitEvent{}
-	case "conferenceCompleted":
-		event = &ConferenceCompletedEvent{}
-	case "conferenceRedirect":
-		event = &ConferenceRedirectEvent{}
-	case "conferenceRecordingAvailable":
-		event = &ConferenceRecordingAvailableEvent{}
-	case "disconnect":
-		event = &DisconnectEvent{}
-	case "gather":
-		event = &GatherEvent{}
-	case "initiate":
-		event = &InitiateEvent{}
-	case "recordComplete":
-		event = &RecordCompleteEvent{}
-	case "recordingAvailable":
-		event = &RecordingAvailableEvent{}
-	case "transcriptionAvailable":
-		event = &TranscriptionAvailableEvent{}
-	case "redirect":
-		event = &RedirectEvent{}
-	case "transferAnswer":
-		event = &TransferAnswerEvent{}
-	case "transferComplete":
-		event = &TransferCompleteEvent{}
-	case "transferDisconnect":
-		event = &TransferDisconnectEvent{}
-	default:
-		return nil, fmt.Errorf("unhandled event type, %v", eventType)
+	eventType, err := readEventType(data)
+	if err != nil {
+		return nil, err
 	}
+	return parseEvent(eventType, data, false)
+}
 
+// readEventType returns the event type of a callback, reading only as far as the eventType field.
+// Fields before it are skipped without being unmarshaled.
+func readEventType(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return "", fmt.Errorf("unable to unmarshal event: %w", err)
+	} else if token != json.Delim('{') {
+		return "", fmt.Errorf("unable to unmarshal event: want object; got %v", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("unable to unmarshal event: %w", err)
+		}
+		if key, _ := token.(string); strings.EqualFold(key, "eventType") {
+			var eventType string
+			if err := decoder.Decode(&eventType); err != nil {
+				return "", fmt.Errorf("unable to unmarshal event type: %w", err)
+			}
+			if eventType == "" {
+				break
+			}
+			return eventType, nil
+		}
+
+		if err := skipValue(decoder); err != nil {
+			return "", fmt.Errorf("unable to unmarshal event: %w", err)
+		}
+	}
+	return "", fmt.Errorf("no event type found")
+}
+
+// skipValue reads the next value, of any depth, from decoder
+func skipValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// parseEvent unmarshals data into the type registered for eventType.  data is copied into a
+// *RawEvent if the caller may reuse it.
+func parseEvent(eventType string, data []byte, copyData bool) (Event, error) {
+	fn, ok := lookupEvent(eventType)
+	if !ok {
+		if copyData {
+			data = append([]byte(nil), data...)
+		}
+		event := &RawEvent{Data: data}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal event, %v: %w", eventType, err)
+		}
+		return event, nil
+	}
+
+	event := fn()
 	if err := json.Unmarshal(data, event); err != nil {
 		return nil, fmt.Errorf("unable to unmarshal event, %v: %w", eventType, err)
 	}
-
 	return event, nil
 }
@@ -1,8 +1,11 @@
 package bandwidth
 
 import (
-	"fmt"
+	"errors"
 	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -31,8 +34,10 @@ func TestParseEvent(t *testing.T) {
 
 	for label, tc := range testCases {
 		t.Run(label, func(t *testing.T) {
-			v := parseEventFile(t, tc.Filename)
-			fmt.Println(v)
+			got := parseEventFile(t, tc.Filename)
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %#v; want %#v", got, tc.Want)
+			}
 		})
 	}
 }
@@ -50,3 +55,163 @@ func parseEventFile(t *testing.T, filename string) Event {
 
 	return event
 }
+
+func TestParseEventVerbCallbacks(t *testing.T) {
+	testCases := map[string]struct {
+		Data string
+		Want Event
+	}{
+		"dtmf": {
+			Data: `{"eventType":"dtmf","callId":"c-1","digit":"5","tag":"menu"}`,
+			Want: &DtmfEvent{EventType: "dtmf", CallId: "c-1", Digit: "5", Tag: "menu"},
+		},
+		"streamStarted": {
+			Data: `{"eventType":"streamStarted","callId":"c-1","streamParams":{"streamId":"s-1","name":"analysis","tracks":"inbound","destination":"wss://example.com"}}`,
+			Want: &StreamStartedEvent{EventType: "streamStarted", CallId: "c-1", StreamParams: StreamParams{StreamId: "s-1", Name: "analysis", Tracks: "inbound", Destination: "wss://example.com"}},
+		},
+		"streamStopped": {
+			Data: `{"eventType":"streamStopped","callId":"c-1","streamParams":{"streamId":"s-1"}}`,
+			Want: &StreamStoppedEvent{EventType: "streamStopped", CallId: "c-1", StreamParams: StreamParams{StreamId: "s-1"}},
+		},
+		"streamRejected": {
+			Data: `{"eventType":"streamRejected","callId":"c-1","errorMessage":"unable to connect"}`,
+			Want: &StreamRejectedEvent{EventType: "streamRejected", CallId: "c-1", ErrorMessage: "unable to connect"},
+		},
+		"transcription": {
+			Data: `{"eventType":"transcription","callId":"c-1","transcription":{"text":"hello","confidence":0.9,"track":"inbound"}}`,
+			Want: &RealTimeTranscriptionEvent{EventType: "transcription", CallId: "c-1", Transcription: RealTimeTranscription{Text: "hello", Confidence: 0.9, Track: "inbound"}},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := ParseEvent([]byte(tc.Data))
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %#v; want %#v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestParseEventUnknown(t *testing.T) {
+	data := []byte(`{"eventType":"somethingNew","callId":"c-1","tag":"x","extra":{"a":1}}`)
+
+	event, err := ParseEvent(data)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	raw, ok := event.(*RawEvent)
+	if !ok {
+		t.Fatalf("got %T; want *RawEvent", event)
+	}
+	if got, want := raw.GetEventType(), "somethingNew"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := raw.GetCallId(), "c-1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := string(raw.Data), string(data); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+type customEvent struct {
+	RawEvent
+	Widget string `json:"widget"`
+}
+
+func TestRegisterEvent(t *testing.T) {
+	RegisterEvent("custom", func() Event { return &customEvent{} })
+
+	event, err := ParseEvent([]byte(`{"eventType":"custom","widget":"blue"}`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	custom, ok := event.(*customEvent)
+	if !ok {
+		t.Fatalf("got %T; want *customEvent", event)
+	}
+	if got, want := custom.Widget, "blue"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRegisterEventBuiltIn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("got nil; want panic")
+		}
+	}()
+	RegisterEvent("answer", func() Event { return &customEvent{} })
+}
+
+func TestDecodeEvent(t *testing.T) {
+	event, err := DecodeEvent(strings.NewReader(`{"eventType":"answer","callId":"c-1"}`))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := event.GetCallId(), "c-1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	large := `{"eventType":"answer","tag":"` + strings.Repeat("x", maxCallbackSize) + `"}`
+	if _, err := DecodeEvent(strings.NewReader(large)); !errors.Is(err, ErrEventTooLarge) {
+		t.Fatalf("got %v; want %v", err, ErrEventTooLarge)
+	}
+
+	if _, err := DecodeEvent(strings.NewReader(`{"callId":"c-1"}`)); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+
+	// the event type need not be the first field, and unknown types keep the complete callback
+	data := `{"callId":"c-2","nested":{"eventType":"answer"},"eventType":"somethingNew"}`
+	event, err = DecodeEvent(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	raw, ok := event.(*RawEvent)
+	if !ok {
+		t.Fatalf("got %T; want *RawEvent", event)
+	}
+	if got, want := string(raw.Data), data; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := raw.GetCallId(), "c-2"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestParseEventFixtures(t *testing.T) {
+	filenames, err := filepath.Glob("testdata/events/*.json")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	found := map[string]bool{}
+	for _, filename := range filenames {
+		eventType := strings.TrimSuffix(filepath.Base(filename), ".json")
+		found[eventType] = true
+
+		t.Run(eventType, func(t *testing.T) {
+			event := parseEventFile(t, filename)
+			if _, ok := event.(*RawEvent); ok {
+				t.Fatalf("got *RawEvent; want typed event")
+			}
+			if got, want := event.GetEventType(), eventType; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if event.GetEventTime().IsZero() {
+				t.Fatalf("got zero event time; want %v", eventType)
+			}
+		})
+	}
+
+	for eventType := range events {
+		if !found[eventType] {
+			t.Fatalf("got no fixture for %v; want testdata/events/%v.json", eventType, eventType)
+		}
+	}
+}
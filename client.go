@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
 )
 
+// maxErrorBodySize limits how much of an error response is read
+const maxErrorBodySize = 512
+
 type Error struct {
 	StatusCode  int    `json:"-"`
 	ID          string `json:"id,omitempty"`
@@ -19,7 +22,14 @@ type Error struct {
 }
 
 func (e Error) Error() string {
-	return e.Type + ": " + e.Description
+	switch {
+	case e.Type == "" && e.Description == "":
+		return fmt.Sprintf("%v %v", e.StatusCode, http.StatusText(e.StatusCode))
+	case e.Type == "":
+		return fmt.Sprintf("%v %v: %v", e.StatusCode, http.StatusText(e.StatusCode), e.Description)
+	default:
+		return e.Type + ": " + e.Description
+	}
 }
 
 type client struct {
@@ -61,10 +71,17 @@ func (c *client) Do(ctx context.Context, method, path string, body, v interface{
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		io.Copy(os.Stdout, resp.Body)
+		// the body may be empty or not json e.g. a 404 page from a proxy; the status code is still
+		// reported, along with the start of a body that isn't json
+		data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		if err != nil {
+			return fmt.Errorf("failed to read error response for %v %v: %w", method, path, err)
+		}
 		var e Error
-		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
-			return fmt.Errorf("retrieved error for request, %v %v: %w", method, path, err)
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := json.Unmarshal(data, &e); err != nil {
+				e = Error{Description: strings.TrimSpace(string(data))}
+			}
 		}
 		e.StatusCode = resp.StatusCode
 		return e
@@ -0,0 +1,55 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/account/json":
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"type":"validation","description":"bad number"}`)
+		case "/account/html":
+			w.WriteHeader(http.StatusBadGateway)
+			io.WriteString(w, "<html>upstream unavailable</html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(server.URL, "account", "username", "password")
+	testCases := map[string]struct {
+		Path   string
+		Status int
+		Want   string
+	}{
+		"json":  {Path: "/json", Status: http.StatusBadRequest, Want: "validation: bad number"},
+		"html":  {Path: "/html", Status: http.StatusBadGateway, Want: "502 Bad Gateway: <html>upstream unavailable</html>"},
+		"empty": {Path: "/empty", Status: http.StatusNotFound, Want: "404 Not Found"},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			err := c.Get(context.Background(), tc.Path, nil)
+
+			var e Error
+			if !errors.As(err, &e) {
+				t.Fatalf("got %v; want Error", err)
+			}
+			if got, want := e.StatusCode, tc.Status; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := strings.TrimSpace(e.Error()), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Command bandwidth-fixture writes realistic Bandwidth callbacks for use as test fixtures.
+//
+//	bandwidth-fixture -seed 42 -tag order-1234 lifecycle
+//	bandwidth-fixture -dir testdata/events all
+//	bandwidth-fixture answer gather disconnect
+//
+// Events are written to stdout, one json document per line, or with -dir to a file per event
+// named after its event type.  All events of one invocation describe the same call.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/savaki/bandwidth/fixture"
+)
+
+func main() {
+	var (
+		seed     = flag.Int64("seed", 1, "seed used to generate ids")
+		tag      = flag.String("tag", "fixture", "tag carried by events")
+		start    = flag.String("start", "2020-01-01T00:00:00Z", "time the call starts, RFC 3339")
+		step     = flag.Duration("step", 2*time.Second, "time between events")
+		outbound = flag.Bool("outbound", false, "generate an outbound call")
+		dir      = flag.String("dir", "", "write each event to <dir>/<eventType>.json instead of stdout")
+		list     = flag.Bool("list", false, "list supported event types")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: bandwidth-fixture [flags] lifecycle|all|<eventType>...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		fmt.Println(strings.Join(fixture.EventTypes(), "\n"))
+		return
+	}
+
+	startTime, err := time.Parse(time.RFC3339, *start)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to parse start, %v: %v\n", *start, err)
+		os.Exit(2)
+	}
+
+	eventTypes := flag.Args()
+	switch {
+	case len(eventTypes) == 0:
+		flag.Usage()
+		os.Exit(2)
+	case len(eventTypes) == 1 && eventTypes[0] == "lifecycle":
+		eventTypes = fixture.CallLifecycle
+	case len(eventTypes) == 1 && eventTypes[0] == "all":
+		eventTypes = fixture.EventTypes()
+	}
+
+	opts := []fixture.Option{
+		fixture.WithSeed(*seed),
+		fixture.WithTag(*tag),
+		fixture.WithStartTime(startTime),
+		fixture.WithStep(*step),
+	}
+	if *outbound {
+		opts = append(opts, fixture.WithOutbound())
+	}
+
+	if err := run(fixture.New(opts...), eventTypes, *dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(g *fixture.Generator, eventTypes []string, dir string) error {
+	for _, eventType := range eventTypes {
+		data, err := g.JSON(eventType)
+		if err != nil {
+			return err
+		}
+
+		if dir == "" {
+			buf := bytes.NewBuffer(nil)
+			if err := json.Compact(buf, data); err != nil {
+				return fmt.Errorf("unable to compact event, %v: %w", eventType, err)
+			}
+			fmt.Println(buf.String())
+			continue
+		}
+
+		filename := filepath.Join(dir, eventType+".json")
+		if err := ioutil.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("unable to write event, %v: %w", filename, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+var (
+	// ErrConferenceLocked is returned by Join once a locked conference refuses new attendees.
+	ErrConferenceLocked = errors.New("conference is locked")
+	// ErrConferenceFull is returned by Join when the conference has reached its participant limit.
+	ErrConferenceFull = errors.New("conference is full")
+)
+
+// ConferenceRole describes how a call participates in a moderated conference
+type ConferenceRole string
+
+const (
+	ConferenceModerator ConferenceRole = "moderator" // ConferenceModerator - runs the conference; never muted or locked out
+	ConferenceAttendee  ConferenceRole = "attendee"  // ConferenceAttendee - regular participant
+)
+
+type conferenceOptions struct {
+	template               bxml.Conference
+	lockOnStart            bool
+	autoMute               bool
+	endWhenModeratorLeaves bool
+	maxParticipants        int
+	pendingTTL             time.Duration
+}
+
+type ConferenceOption func(*conferenceOptions)
+
+// WithConferenceTemplate provides the <Conference> attributes (callback urls, credentials, etc) used
+// for every member that joins
+func WithConferenceTemplate(template bxml.Conference) ConferenceOption {
+	return func(o *conferenceOptions) {
+		o.template = template
+	}
+}
+
+// WithLockOnStart refuses new attendees once the first moderator has joined
+func WithLockOnStart() ConferenceOption {
+	return func(o *conferenceOptions) {
+		o.lockOnStart = true
+	}
+}
+
+// WithAutoMute joins attendees muted
+func WithAutoMute() ConferenceOption {
+	return func(o *conferenceOptions) {
+		o.autoMute = true
+	}
+}
+
+// WithEndWhenModeratorLeaves completes the conference when the last moderator exits
+func WithEndWhenModeratorLeaves() ConferenceOption {
+	return func(o *conferenceOptions) {
+		o.endWhenModeratorLeaves = true
+	}
+}
+
+// WithMaxParticipants limits the number of members, moderators included; 0 means no limit
+func WithMaxParticipants(n int) ConferenceOption {
+	return func(o *conferenceOptions) {
+		o.maxParticipants = n
+	}
+}
+
+// WithPendingTTL sets how long a place reserved by Join is held for a call that has not joined;
+// defaults to 2m.  A reservation is also released when the call disconnects.
+func WithPendingTTL(d time.Duration) ConferenceOption {
+	return func(o *conferenceOptions) {
+		o.pendingTTL = d
+	}
+}
+
+// pendingMember is a call that has been given BXML, but has not yet joined
+type pendingMember struct {
+	role    ConferenceRole
+	expires time.Time
+}
+
+// ConferenceController runs a moderated conference using only the <Conference> verb, the
+// conference member callbacks, and the conference member api.
+//
+// Use Join to generate the BXML for each call and pass every conference callback, and the
+// disconnect callbacks of its calls, to HandleEvent.
+type ConferenceController struct {
+	voice   *Voice
+	name    string
+	options conferenceOptions
+	now     func() time.Time
+
+	mu           sync.Mutex
+	conferenceId string
+	started      bool
+	locked       bool
+	ended        bool
+	pending      map[string]pendingMember  // pending contains calls that have been given BXML, but have not yet joined
+	members      map[string]ConferenceRole // members contains calls currently in the conference
+}
+
+// NewConferenceController returns a controller for the conference with the given name
+func NewConferenceController(voice *Voice, name string, opts ...ConferenceOption) *ConferenceController {
+	options := conferenceOptions{
+		pendingTTL: 2 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &ConferenceController{
+		voice:   voice,
+		name:    name,
+		options: options,
+		now:     time.Now,
+		pending: map[string]pendingMember{},
+		members: map[string]ConferenceRole{},
+	}
+}
+
+// Join reserves a place in the conference for the call and returns the <Conference> verb the call
+// should execute.  Returns ErrConferenceLocked or ErrConferenceFull if the call may not join.
+func (c *ConferenceController) Join(callId string, role ConferenceRole) (bxml.Conference, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expirePending()
+	if role != ConferenceModerator {
+		if c.locked || c.ended {
+			return bxml.Conference{}, ErrConferenceLocked
+		}
+	}
+	if _, ok := c.members[callId]; !ok {
+		if _, ok := c.pending[callId]; !ok && c.isFull() {
+			return bxml.Conference{}, ErrConferenceFull
+		}
+	}
+	c.pending[callId] = pendingMember{role: role, expires: c.now().Add(c.options.pendingTTL)}
+
+	verb := c.options.template
+	verb.Name = c.name
+	verb.Mute = role != ConferenceModerator && c.options.autoMute
+	return verb, nil
+}
+
+// ConferenceId returns the id Bandwidth assigned to the conference or "" if no member has joined yet
+func (c *ConferenceController) ConferenceId() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conferenceId
+}
+
+// Started returns true once a moderator has joined
+func (c *ConferenceController) Started() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.started
+}
+
+// Locked returns true if the conference no longer accepts attendees
+func (c *ConferenceController) Locked() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.locked
+}
+
+// Lock refuses any further attendees
+func (c *ConferenceController) Lock() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locked = true
+}
+
+// Unlock allows attendees to join again
+func (c *ConferenceController) Unlock() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locked = false
+}
+
+// Members returns the call ids of the current members and their roles
+func (c *ConferenceController) Members() map[string]ConferenceRole {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	members := make(map[string]ConferenceRole, len(c.members))
+	for callId, role := range c.members {
+		members[callId] = role
+	}
+	return members
+}
+
+// HandleEvent updates the conference from a conference callback.  Events for other conferences are
+// ignored.  A disconnect releases any place reserved for the call by Join.
+func (c *ConferenceController) HandleEvent(ctx context.Context, event Event) error {
+	if v, ok := event.(*DisconnectEvent); ok {
+		c.mu.Lock()
+		delete(c.pending, v.CallId)
+		c.mu.Unlock()
+		return nil
+	}
+	if e, ok := event.(ConferenceEvent); !ok || e.GetName() != c.name {
+		return nil
+	}
+
+	switch v := event.(type) {
+	case *ConferenceCreatedEvent:
+		c.setConferenceId(v.ConferenceId)
+	case *ConferenceMemberJoinEvent:
+		return c.memberJoined(ctx, v)
+	case *ConferenceMemberExitEvent:
+		return c.memberExited(ctx, v)
+	case *ConferenceCompletedEvent:
+		c.mu.Lock()
+		c.ended = true
+		c.members = map[string]ConferenceRole{}
+		c.pending = map[string]pendingMember{}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *ConferenceController) setConferenceId(conferenceId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if conferenceId != "" {
+		c.conferenceId = conferenceId
+	}
+}
+
+func (c *ConferenceController) memberJoined(ctx context.Context, event *ConferenceMemberJoinEvent) error {
+	c.mu.Lock()
+	if event.ConferenceId != "" {
+		c.conferenceId = event.ConferenceId
+	}
+
+	c.expirePending()
+	pending, ok := c.pending[event.CallId]
+	delete(c.pending, event.CallId)
+	role := pending.role
+
+	// calls that did not join via Join are attendees and are subject to the same rules
+	admit := ok || (!c.locked && !c.ended && !c.isFull())
+	if !ok {
+		role = ConferenceAttendee
+	}
+	if admit {
+		c.members[event.CallId] = role
+		if role == ConferenceModerator {
+			c.started = true
+			if c.options.lockOnStart {
+				c.locked = true
+			}
+		}
+	}
+	mute := admit && !ok && c.options.autoMute
+	c.mu.Unlock()
+
+	if !admit {
+		input := UpdateCallInput{
+			CallId: event.CallId,
+			State:  "completed",
+		}
+		if err := c.voice.UpdateCall(ctx, input); err != nil {
+			return fmt.Errorf("unable to remove call, %v, from conference, %v: %w", event.CallId, c.name, err)
+		}
+		return nil
+	}
+
+	if mute {
+		return c.Mute(ctx, event.CallId, true)
+	}
+
+	return nil
+}
+
+func (c *ConferenceController) memberExited(ctx context.Context, event *ConferenceMemberExitEvent) error {
+	c.mu.Lock()
+	role, ok := c.members[event.CallId]
+	delete(c.members, event.CallId)
+	delete(c.pending, event.CallId)
+
+	end := ok && role == ConferenceModerator && c.options.endWhenModeratorLeaves && !c.ended && !c.hasModerator()
+	c.mu.Unlock()
+
+	if end {
+		return c.End(ctx)
+	}
+	return nil
+}
+
+// Mute mutes or unmutes a member of the conference
+func (c *ConferenceController) Mute(ctx context.Context, callId string, mute bool) error {
+	input := UpdateConferenceMemberInput{
+		ConferenceId: c.ConferenceId(),
+		MemberId:     callId,
+		Mute:         fmt.Sprint(mute),
+	}
+	if err := c.voice.UpdateConferenceMember(ctx, input); err != nil {
+		return fmt.Errorf("unable to mute member, %v, of conference, %v: %w", callId, c.name, err)
+	}
+	return nil
+}
+
+// MuteAttendees mutes or unmutes every attendee currently in the conference
+func (c *ConferenceController) MuteAttendees(ctx context.Context, mute bool) error {
+	for callId, role := range c.Members() {
+		if role == ConferenceModerator {
+			continue
+		}
+		if err := c.Mute(ctx, callId, mute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// End completes the conference, disconnecting all members
+func (c *ConferenceController) End(ctx context.Context) error {
+	c.mu.Lock()
+	c.ended = true
+	conferenceId := c.conferenceId
+	c.mu.Unlock()
+
+	input := UpdateConferenceInput{
+		ConferenceId: conferenceId,
+		Status:       "completed",
+	}
+	if err := c.voice.UpdateConference(ctx, input); err != nil {
+		return fmt.Errorf("unable to end conference, %v: %w", c.name, err)
+	}
+	return nil
+}
+
+// expirePending releases reservations for calls that never joined; must be called while holding
+// the lock
+func (c *ConferenceController) expirePending() {
+	now := c.now()
+	for callId, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, callId)
+		}
+	}
+}
+
+// isFull must be called while holding the lock
+func (c *ConferenceController) isFull() bool {
+	if c.options.maxParticipants <= 0 {
+		return false
+	}
+	c.expirePending()
+	return len(c.members)+len(c.pending) >= c.options.maxParticipants
+}
+
+// hasModerator must be called while holding the lock
+func (c *ConferenceController) hasModerator() bool {
+	for _, role := range c.members {
+		if role == ConferenceModerator {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package bandwidth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// newTestVoice returns a Voice that records requests made against it rather than calling bandwidth
+func newTestVoice() (*Voice, func() []recordedRequest, func()) {
+	var (
+		mu       sync.Mutex
+		requests []recordedRequest
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{Method: req.Method, Path: req.URL.Path, Body: string(data)})
+		mu.Unlock()
+	}))
+
+	voice := &Voice{client: newClient(server.URL, "account", "username", "password")}
+	return voice, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}, server.Close
+}
+
+func TestConferenceController(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("moderated", func(t *testing.T) {
+		voice, requests, done := newTestVoice()
+		defer done()
+
+		controller := NewConferenceController(voice, "town-hall",
+			WithAutoMute(),
+			WithLockOnStart(),
+			WithEndWhenModeratorLeaves(),
+		)
+
+		attendee, err := controller.Join("c-attendee", ConferenceAttendee)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := attendee.Name, "town-hall"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if !attendee.Mute {
+			t.Fatalf("got false; want attendee muted")
+		}
+
+		moderator, err := controller.Join("c-moderator", ConferenceModerator)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if moderator.Mute {
+			t.Fatalf("got true; want moderator unmuted")
+		}
+
+		for _, callId := range []string{"c-attendee", "c-moderator"} {
+			event := &ConferenceMemberJoinEvent{ConferenceId: "conf-1", Name: "town-hall", CallId: callId}
+			if err := controller.HandleEvent(ctx, event); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+		}
+		if !controller.Started() || !controller.Locked() {
+			t.Fatalf("got started=%v locked=%v; want started and locked", controller.Started(), controller.Locked())
+		}
+
+		if _, err := controller.Join("c-late", ConferenceAttendee); err != ErrConferenceLocked {
+			t.Fatalf("got %v; want %v", err, ErrConferenceLocked)
+		}
+
+		exit := &ConferenceMemberExitEvent{ConferenceId: "conf-1", Name: "town-hall", CallId: "c-moderator"}
+		if err := controller.HandleEvent(ctx, exit); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		got := requests()
+		if len(got) != 1 {
+			t.Fatalf("got %v requests; want 1", len(got))
+		}
+		if got, want := got[0].Path, "/account/conferences/conf-1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got[0].Body, `{"status":"completed"}`; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("max participants", func(t *testing.T) {
+		voice, requests, done := newTestVoice()
+		defer done()
+
+		controller := NewConferenceController(voice, "bridge", WithMaxParticipants(1), WithAutoMute())
+
+		if _, err := controller.Join("c-1", ConferenceAttendee); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if _, err := controller.Join("c-2", ConferenceAttendee); err != ErrConferenceFull {
+			t.Fatalf("got %v; want %v", err, ErrConferenceFull)
+		}
+
+		// a call that joined without using Join is removed when the conference is full
+		join := &ConferenceMemberJoinEvent{ConferenceId: "conf-2", Name: "bridge", CallId: "c-3"}
+		if err := controller.HandleEvent(ctx, join); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		got := requests()
+		if len(got) != 1 {
+			t.Fatalf("got %v requests; want 1", len(got))
+		}
+		if got, want := got[0].Path, "/account/calls/c-3"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("ignores other conferences", func(t *testing.T) {
+		voice, requests, done := newTestVoice()
+		defer done()
+
+		controller := NewConferenceController(voice, "mine", WithAutoMute())
+
+		join := &ConferenceMemberJoinEvent{ConferenceId: "conf-3", Name: "theirs", CallId: "c-1"}
+		if err := controller.HandleEvent(ctx, join); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := len(requests()); got != 0 {
+			t.Fatalf("got %v requests; want 0", got)
+		}
+		if got := len(controller.Members()); got != 0 {
+			t.Fatalf("got %v members; want 0", got)
+		}
+	})
+
+	t.Run("pending expires", func(t *testing.T) {
+		voice, _, done := newTestVoice()
+		defer done()
+
+		now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		controller := NewConferenceController(voice, "small", WithMaxParticipants(1), WithPendingTTL(time.Minute))
+		controller.now = func() time.Time { return now }
+
+		if _, err := controller.Join("c-1", ConferenceAttendee); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if _, err := controller.Join("c-2", ConferenceAttendee); err != ErrConferenceFull {
+			t.Fatalf("got %v; want %v", err, ErrConferenceFull)
+		}
+
+		// c-1 never joins, so its place is released once the reservation expires
+		now = now.Add(2 * time.Minute)
+		if _, err := controller.Join("c-2", ConferenceAttendee); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		// and immediately when the call disconnects
+		if err := controller.HandleEvent(ctx, &DisconnectEvent{EventType: "disconnect", CallId: "c-2"}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if _, err := controller.Join("c-3", ConferenceAttendee); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package bandwidth
+
+import "time"
+
+// parseEventTime parses the ISO 8601 time of an event; returns the zero time if s is empty or invalid
+func parseEventTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func (e AnswerEvent) GetEventType() string     { return e.EventType }
+func (e AnswerEvent) GetAccountId() string     { return e.AccountId }
+func (e AnswerEvent) GetApplicationId() string { return e.ApplicationId }
+func (e AnswerEvent) GetCallId() string        { return e.CallId }
+func (e AnswerEvent) GetTag() string           { return e.Tag }
+func (e AnswerEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e BridgeCompleteEvent) GetEventType() string     { return e.EventType }
+func (e BridgeCompleteEvent) GetAccountId() string     { return e.AccountId }
+func (e BridgeCompleteEvent) GetApplicationId() string { return e.ApplicationId }
+func (e BridgeCompleteEvent) GetCallId() string        { return e.CallId }
+func (e BridgeCompleteEvent) GetTag() string           { return e.Tag }
+func (e BridgeCompleteEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e BridgeTargetCompleteEvent) GetEventType() string     { return e.EventType }
+func (e BridgeTargetCompleteEvent) GetAccountId() string     { return e.AccountId }
+func (e BridgeTargetCompleteEvent) GetApplicationId() string { return e.ApplicationId }
+func (e BridgeTargetCompleteEvent) GetCallId() string        { return e.CallId }
+func (e BridgeTargetCompleteEvent) GetTag() string           { return e.Tag }
+func (e BridgeTargetCompleteEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e ConferenceCreatedEvent) GetEventType() string     { return e.EventType }
+func (e ConferenceCreatedEvent) GetAccountId() string     { return "" }
+func (e ConferenceCreatedEvent) GetApplicationId() string { return "" }
+func (e ConferenceCreatedEvent) GetCallId() string        { return "" }
+func (e ConferenceCreatedEvent) GetTag() string           { return e.Tag }
+func (e ConferenceCreatedEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+func (e ConferenceCreatedEvent) GetConferenceId() string  { return e.ConferenceId }
+func (e ConferenceCreatedEvent) GetName() string          { return e.Name }
+
+func (e ConferenceMemberJoinEvent) GetEventType() string     { return e.EventType }
+func (e ConferenceMemberJoinEvent) GetAccountId() string     { return "" }
+func (e ConferenceMemberJoinEvent) GetApplicationId() string { return "" }
+func (e ConferenceMemberJoinEvent) GetCallId() string        { return e.CallId }
+func (e ConferenceMemberJoinEvent) GetTag() string           { return e.Tag }
+func (e ConferenceMemberJoinEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+func (e ConferenceMemberJoinEvent) GetConferenceId() string  { return e.ConferenceId }
+func (e ConferenceMemberJoinEvent) GetName() string          { return e.Name }
+
+func (e ConferenceMemberExitEvent) GetEventType() string     { return e.EventType }
+func (e ConferenceMemberExitEvent) GetAccountId() string     { return "" }
+func (e ConferenceMemberExitEvent) GetApplicationId() string { return "" }
+func (e ConferenceMemberExitEvent) GetCallId() string        { return e.CallId }
+func (e ConferenceMemberExitEvent) GetTag() string           { return e.Tag }
+func (e ConferenceMemberExitEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+func (e ConferenceMemberExitEvent) GetConferenceId() string  { return e.ConferenceId }
+func (e ConferenceMemberExitEvent) GetName() string          { return e.Name }
+
+func (e ConferenceCompletedEvent) GetEventType() string     { return e.EventType }
+func (e ConferenceCompletedEvent) GetAccountId() string     { return "" }
+func (e ConferenceCompletedEvent) GetApplicationId() string { return "" }
+func (e ConferenceCompletedEvent) GetCallId() string        { return "" }
+func (e ConferenceCompletedEvent) GetTag() string           { return e.Tag }
+func (e ConferenceCompletedEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+func (e ConferenceCompletedEvent) GetConferenceId() string  { return e.ConferenceId }
+func (e ConferenceCompletedEvent) GetName() string          { return e.Name }
+
+func (e ConferenceRedirectEvent) GetEventType() string     { return e.EventType }
+func (e ConferenceRedirectEvent) GetAccountId() string     { return "" }
+func (e ConferenceRedirectEvent) GetApplicationId() string { return "" }
+func (e ConferenceRedirectEvent) GetCallId() string        { return "" }
+func (e ConferenceRedirectEvent) GetTag() string           { return "" }
+func (e ConferenceRedirectEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+func (e ConferenceRedirectEvent) GetConferenceId() string  { return e.ConferenceId }
+func (e ConferenceRedirectEvent) GetName() string          { return e.Name }
+
+func (e ConferenceRecordingAvailableEvent) GetEventType() string     { return e.EventType }
+func (e ConferenceRecordingAvailableEvent) GetAccountId() string     { return e.AccountId }
+func (e ConferenceRecordingAvailableEvent) GetApplicationId() string { return "" }
+func (e ConferenceRecordingAvailableEvent) GetCallId() string        { return "" }
+func (e ConferenceRecordingAvailableEvent) GetTag() string           { return e.Tag }
+func (e ConferenceRecordingAvailableEvent) GetEventTime() time.Time {
+	return parseEventTime(e.EventTime)
+}
+func (e ConferenceRecordingAvailableEvent) GetConferenceId() string { return e.ConferenceId }
+func (e ConferenceRecordingAvailableEvent) GetName() string         { return e.Name }
+func (e ConferenceRecordingAvailableEvent) GetRecordingId() string  { return e.RecordingId }
+func (e ConferenceRecordingAvailableEvent) GetMediaUrl() string     { return e.MediaUrl }
+
+func (e DisconnectEvent) GetEventType() string     { return e.EventType }
+func (e DisconnectEvent) GetAccountId() string     { return e.AccountId }
+func (e DisconnectEvent) GetApplicationId() string { return e.ApplicationId }
+func (e DisconnectEvent) GetCallId() string        { return e.CallId }
+func (e DisconnectEvent) GetTag() string           { return e.Tag }
+func (e DisconnectEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e GatherEvent) GetEventType() string        { return e.EventType }
+func (e GatherEvent) GetAccountId() string        { return e.AccountId }
+func (e GatherEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e GatherEvent) GetCallId() string           { return e.CallId }
+func (e GatherEvent) GetTag() string              { return e.Tag }
+func (e GatherEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e GatherEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e GatherEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e GatherEvent) GetTransferTo() string       { return e.TransferTo }
+
+func (e InitiateEvent) GetEventType() string     { return e.EventType }
+func (e InitiateEvent) GetAccountId() string     { return e.AccountId }
+func (e InitiateEvent) GetApplicationId() string { return e.ApplicationId }
+func (e InitiateEvent) GetCallId() string        { return e.CallId }
+func (e InitiateEvent) GetTag() string           { return "" }
+func (e InitiateEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e RecordCompleteEvent) GetEventType() string        { return e.EventType }
+func (e RecordCompleteEvent) GetAccountId() string        { return e.AccountId }
+func (e RecordCompleteEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e RecordCompleteEvent) GetCallId() string           { return e.CallId }
+func (e RecordCompleteEvent) GetTag() string              { return e.Tag }
+func (e RecordCompleteEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e RecordCompleteEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e RecordCompleteEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e RecordCompleteEvent) GetTransferTo() string       { return e.TransferTo }
+func (e RecordCompleteEvent) GetRecordingId() string      { return e.RecordingId }
+func (e RecordCompleteEvent) GetMediaUrl() string         { return e.MediaUrl }
+
+func (e RecordingAvailableEvent) GetEventType() string        { return e.EventType }
+func (e RecordingAvailableEvent) GetAccountId() string        { return e.AccountId }
+func (e RecordingAvailableEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e RecordingAvailableEvent) GetCallId() string           { return e.CallId }
+func (e RecordingAvailableEvent) GetTag() string              { return e.Tag }
+func (e RecordingAvailableEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e RecordingAvailableEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e RecordingAvailableEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e RecordingAvailableEvent) GetTransferTo() string       { return e.TransferTo }
+func (e RecordingAvailableEvent) GetRecordingId() string      { return e.RecordingId }
+func (e RecordingAvailableEvent) GetMediaUrl() string         { return e.MediaUrl }
+
+func (e TranscriptionAvailableEvent) GetEventType() string        { return e.EventType }
+func (e TranscriptionAvailableEvent) GetAccountId() string        { return e.AccountId }
+func (e TranscriptionAvailableEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e TranscriptionAvailableEvent) GetCallId() string           { return e.CallId }
+func (e TranscriptionAvailableEvent) GetTag() string              { return e.Tag }
+func (e TranscriptionAvailableEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e TranscriptionAvailableEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e TranscriptionAvailableEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e TranscriptionAvailableEvent) GetTransferTo() string       { return e.TransferTo }
+func (e TranscriptionAvailableEvent) GetRecordingId() string      { return e.RecordingId }
+func (e TranscriptionAvailableEvent) GetMediaUrl() string         { return e.MediaUrl }
+
+func (e RedirectEvent) GetEventType() string        { return e.EventType }
+func (e RedirectEvent) GetAccountId() string        { return e.AccountId }
+func (e RedirectEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e RedirectEvent) GetCallId() string           { return e.CallId }
+func (e RedirectEvent) GetTag() string              { return e.Tag }
+func (e RedirectEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e RedirectEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e RedirectEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e RedirectEvent) GetTransferTo() string       { return e.TransferTo }
+
+func (e TransferAnswerEvent) GetEventType() string        { return e.EventType }
+func (e TransferAnswerEvent) GetAccountId() string        { return e.AccountId }
+func (e TransferAnswerEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e TransferAnswerEvent) GetCallId() string           { return e.CallId }
+func (e TransferAnswerEvent) GetTag() string              { return e.Tag }
+func (e TransferAnswerEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e TransferAnswerEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e TransferAnswerEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e TransferAnswerEvent) GetTransferTo() string       { return e.TransferTo }
+
+func (e TransferCompleteEvent) GetEventType() string     { return e.EventType }
+func (e TransferCompleteEvent) GetAccountId() string     { return e.AccountId }
+func (e TransferCompleteEvent) GetApplicationId() string { return e.ApplicationId }
+func (e TransferCompleteEvent) GetCallId() string        { return e.CallId }
+func (e TransferCompleteEvent) GetTag() string           { return e.Tag }
+func (e TransferCompleteEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e TransferDisconnectEvent) GetEventType() string        { return e.EventType }
+func (e TransferDisconnectEvent) GetAccountId() string        { return e.AccountId }
+func (e TransferDisconnectEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e TransferDisconnectEvent) GetCallId() string           { return e.CallId }
+func (e TransferDisconnectEvent) GetTag() string              { return e.Tag }
+func (e TransferDisconnectEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e TransferDisconnectEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e TransferDisconnectEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e TransferDisconnectEvent) GetTransferTo() string       { return e.TransferTo }
+
+func (e DtmfEvent) GetEventType() string        { return e.EventType }
+func (e DtmfEvent) GetAccountId() string        { return e.AccountId }
+func (e DtmfEvent) GetApplicationId() string    { return e.ApplicationId }
+func (e DtmfEvent) GetCallId() string           { return e.CallId }
+func (e DtmfEvent) GetTag() string              { return e.Tag }
+func (e DtmfEvent) GetEventTime() time.Time     { return parseEventTime(e.EventTime) }
+func (e DtmfEvent) GetParentCallId() string     { return e.ParentCallId }
+func (e DtmfEvent) GetTransferCallerId() string { return e.TransferCallerId }
+func (e DtmfEvent) GetTransferTo() string       { return e.TransferTo }
+
+func (e StreamStartedEvent) GetEventType() string     { return e.EventType }
+func (e StreamStartedEvent) GetAccountId() string     { return e.AccountId }
+func (e StreamStartedEvent) GetApplicationId() string { return e.ApplicationId }
+func (e StreamStartedEvent) GetCallId() string        { return e.CallId }
+func (e StreamStartedEvent) GetTag() string           { return e.Tag }
+func (e StreamStartedEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e StreamStoppedEvent) GetEventType() string     { return e.EventType }
+func (e StreamStoppedEvent) GetAccountId() string     { return e.AccountId }
+func (e StreamStoppedEvent) GetApplicationId() string { return e.ApplicationId }
+func (e StreamStoppedEvent) GetCallId() string        { return e.CallId }
+func (e StreamStoppedEvent) GetTag() string           { return e.Tag }
+func (e StreamStoppedEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e StreamRejectedEvent) GetEventType() string     { return e.EventType }
+func (e StreamRejectedEvent) GetAccountId() string     { return e.AccountId }
+func (e StreamRejectedEvent) GetApplicationId() string { return e.ApplicationId }
+func (e StreamRejectedEvent) GetCallId() string        { return e.CallId }
+func (e StreamRejectedEvent) GetTag() string           { return e.Tag }
+func (e StreamRejectedEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e RealTimeTranscriptionEvent) GetEventType() string     { return e.EventType }
+func (e RealTimeTranscriptionEvent) GetAccountId() string     { return e.AccountId }
+func (e RealTimeTranscriptionEvent) GetApplicationId() string { return e.ApplicationId }
+func (e RealTimeTranscriptionEvent) GetCallId() string        { return e.CallId }
+func (e RealTimeTranscriptionEvent) GetTag() string           { return e.Tag }
+func (e RealTimeTranscriptionEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e MachineDetectionCompleteEvent) GetEventType() string     { return e.EventType }
+func (e MachineDetectionCompleteEvent) GetAccountId() string     { return e.AccountId }
+func (e MachineDetectionCompleteEvent) GetApplicationId() string { return e.ApplicationId }
+func (e MachineDetectionCompleteEvent) GetCallId() string        { return e.CallId }
+func (e MachineDetectionCompleteEvent) GetTag() string           { return e.Tag }
+func (e MachineDetectionCompleteEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
+
+func (e RawEvent) GetEventType() string     { return e.EventType }
+func (e RawEvent) GetAccountId() string     { return e.AccountId }
+func (e RawEvent) GetApplicationId() string { return e.ApplicationId }
+func (e RawEvent) GetCallId() string        { return e.CallId }
+func (e RawEvent) GetTag() string           { return e.Tag }
+func (e RawEvent) GetEventTime() time.Time  { return parseEventTime(e.EventTime) }
@@ -0,0 +1,71 @@
+package bandwidth
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	_ ConferenceEvent = ConferenceMemberJoinEvent{}
+	_ TransferEvent   = &GatherEvent{}
+	_ RecordingEvent  = RecordCompleteEvent{}
+	_ RecordingEvent  = ConferenceRecordingAvailableEvent{}
+)
+
+func TestEventAccessors(t *testing.T) {
+	data := []byte(`{
+		"eventType": "gather",
+		"eventTime": "2019-06-20T15:54:25.432Z",
+		"accountId": "55555555",
+		"applicationId": "7fc9698a-b04a-468b-9e8f-91238c0d0086",
+		"callId": "c-123",
+		"parentCallId": "c-456",
+		"tag": "example-tag",
+		"digits": "1"
+	}`)
+
+	event, err := ParseEvent(data)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := event.GetEventType(), "gather"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := event.GetAccountId(), "55555555"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := event.GetApplicationId(), "7fc9698a-b04a-468b-9e8f-91238c0d0086"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := event.GetCallId(), "c-123"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := event.GetTag(), "example-tag"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := event.GetEventTime(), time.Date(2019, 6, 20, 15, 54, 25, 432000000, time.UTC); !got.Equal(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	transfer, ok := event.(TransferEvent)
+	if !ok {
+		t.Fatalf("got %T; want TransferEvent", event)
+	}
+	if got, want := transfer.GetParentCallId(), "c-456"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if _, ok := event.(ConferenceEvent); ok {
+		t.Fatalf("got ConferenceEvent; want gather to not be a conference event")
+	}
+}
+
+func TestEventTimeMissing(t *testing.T) {
+	event := &ConferenceCreatedEvent{EventType: "conferenceCreated", Name: "standup"}
+	if got := event.GetEventTime(); !got.IsZero() {
+		t.Fatalf("got %v; want zero time", got)
+	}
+	if got := event.GetCallId(); got != "" {
+		t.Fatalf("got %v; want empty call id", got)
+	}
+}
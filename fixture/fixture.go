@@ -0,0 +1,465 @@
+// Package fixture generates realistic callbacks for tests.  Events from a Generator describe one
+// consistent call: they share a call id, carry the same tag, and have ordered timestamps.  Output
+// depends only on the options, so the same seed always produces the same events.
+//
+//	g := fixture.New(fixture.WithSeed(42), fixture.WithTag("order-1234"))
+//	events, err := g.Lifecycle()
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/savaki/bandwidth"
+)
+
+// timeFormat is the ISO 8601 format Bandwidth uses for callback times
+const timeFormat = "2006-01-02T15:04:05.000Z"
+
+// CallLifecycle is the sequence of callbacks for an inbound call that answers, collects digits,
+// records a message, and hangs up
+var CallLifecycle = []string{
+	"initiate",
+	"answer",
+	"gather",
+	"redirect",
+	"recordComplete",
+	"disconnect",
+	"recordingAvailable",
+	"transcriptionAvailable",
+}
+
+type options struct {
+	seed          int64
+	start         time.Time
+	step          time.Duration
+	accountId     string
+	applicationId string
+	from          string
+	to            string
+	direction     string
+	tag           string
+}
+
+// Option configures a Generator
+type Option func(*options)
+
+// WithSeed sets the seed used to generate ids; defaults to 1
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// WithStartTime sets the time of the first event; defaults to 2020-01-01T00:00:00Z
+func WithStartTime(t time.Time) Option {
+	return func(o *options) {
+		o.start = t.UTC()
+	}
+}
+
+// WithStep sets the time between events; defaults to 2s
+func WithStep(d time.Duration) Option {
+	return func(o *options) {
+		o.step = d
+	}
+}
+
+// WithAccount sets the account and application ids of events
+func WithAccount(accountId, applicationId string) Option {
+	return func(o *options) {
+		o.accountId = accountId
+		o.applicationId = applicationId
+	}
+}
+
+// WithNumbers sets the from and to numbers of the call
+func WithNumbers(from, to string) Option {
+	return func(o *options) {
+		o.from = from
+		o.to = to
+	}
+}
+
+// WithOutbound generates events for an outbound call; calls are inbound by default
+func WithOutbound() Option {
+	return func(o *options) {
+		o.direction = "outbound"
+	}
+}
+
+// WithTag sets the tag carried by events; defaults to fixture
+func WithTag(tag string) Option {
+	return func(o *options) {
+		o.tag = tag
+	}
+}
+
+// Generator generates the callbacks of a single call.  Each event advances the clock by the step.
+// Generator is not safe for concurrent use.
+type Generator struct {
+	options options
+	rand    *rand.Rand
+	now     time.Time
+
+	callId       string
+	answerTime   time.Time
+	conferenceId string
+	recordingId  string
+	recordStart  time.Time
+	recordEnd    time.Time
+	transferId   string
+	transferTime time.Time
+	streamId     string
+}
+
+// New returns a new Generator
+func New(opts ...Option) *Generator {
+	options := options{
+		seed:      1,
+		start:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		step:      2 * time.Second,
+		accountId: "5500000",
+		from:      "+15555550100",
+		to:        "+15555550199",
+		direction: "inbound",
+		tag:       "fixture",
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	g := &Generator{
+		options: options,
+		rand:    rand.New(rand.NewSource(options.seed)),
+		now:     options.start,
+	}
+	if g.options.applicationId == "" {
+		g.options.applicationId = g.uuid()
+	}
+	g.callId = "c-" + g.hex(8) + "-" + g.uuid()
+	g.conferenceId = "conf-" + g.hex(8) + "-" + g.uuid()
+	return g
+}
+
+// EventTypes returns the event types the Generator supports, sorted
+func EventTypes() []string {
+	var eventTypes []string
+	for eventType := range builders {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
+
+// CallId returns the id of the generated call
+func (g *Generator) CallId() string {
+	return g.callId
+}
+
+// Lifecycle returns the events of CallLifecycle, in order
+func (g *Generator) Lifecycle() ([]bandwidth.Event, error) {
+	return g.Events(CallLifecycle...)
+}
+
+// Events returns an event for each of the given event types, in order
+func (g *Generator) Events(eventTypes ...string) ([]bandwidth.Event, error) {
+	var events []bandwidth.Event
+	for _, eventType := range eventTypes {
+		event, err := g.Event(eventType)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Event returns the next event of the call with the given event type
+func (g *Generator) Event(eventType string) (bandwidth.Event, error) {
+	data, err := g.JSON(eventType)
+	if err != nil {
+		return nil, err
+	}
+	return bandwidth.ParseEvent(data)
+}
+
+// JSON returns the next event of the call with the given event type as indented json
+func (g *Generator) JSON(eventType string) ([]byte, error) {
+	build, ok := builders[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unable to generate event, %v: unknown event type", eventType)
+	}
+
+	g.now = g.now.Add(g.options.step)
+	fields := map[string]interface{}{
+		"eventType": eventType,
+		"eventTime": format(g.now),
+	}
+	build(g, fields)
+
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal event, %v: %w", eventType, err)
+	}
+	return data, nil
+}
+
+// builders add the fields of each event type
+var builders = map[string]func(g *Generator, fields map[string]interface{}){
+	"answer": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+	},
+	"bridgeComplete": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		fields["cause"] = "hangup"
+	},
+	"bridgeTargetComplete": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+	},
+	"conferenceCreated": func(g *Generator, fields map[string]interface{}) {
+		g.conference(fields)
+	},
+	"conferenceMemberJoin": func(g *Generator, fields map[string]interface{}) {
+		g.conference(fields)
+		g.member(fields)
+	},
+	"conferenceMemberExit": func(g *Generator, fields map[string]interface{}) {
+		g.conference(fields)
+		g.member(fields)
+	},
+	"conferenceCompleted": func(g *Generator, fields map[string]interface{}) {
+		g.conference(fields)
+	},
+	"conferenceRedirect": func(g *Generator, fields map[string]interface{}) {
+		g.conference(fields)
+		delete(fields, "tag")
+	},
+	"conferenceRecordingAvailable": func(g *Generator, fields map[string]interface{}) {
+		g.conference(fields)
+		g.recording(fields)
+		fields["accountId"] = g.options.accountId
+		fields["status"] = "complete"
+		fields["mediaUrl"] = fmt.Sprintf("https://voice.bandwidth.com/api/v2/accounts/%v/conferences/%v/recordings/%v/media", g.options.accountId, g.conferenceId, g.recordingId)
+	},
+	"disconnect": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		fields["endTime"] = format(g.now)
+		fields["cause"] = "hangup"
+	},
+	"dtmf": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		fields["digit"] = g.digit()
+	},
+	"gather": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		fields["digits"] = g.digit()
+		fields["terminatingDigit"] = "#"
+	},
+	"initiate": func(g *Generator, fields map[string]interface{}) {
+		g.call(fields)
+		delete(fields, "answerTime")
+		delete(fields, "tag")
+	},
+	"machineDetectionComplete": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		fields["machineDetectionResult"] = map[string]interface{}{
+			"value":    "human",
+			"duration": "PT4.5S",
+		}
+	},
+	"recordComplete": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		g.recording(fields)
+	},
+	"recordingAvailable": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		g.recording(fields)
+		fields["status"] = "complete"
+	},
+	"redirect": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+	},
+	"streamStarted": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		g.stream(fields)
+	},
+	"streamStopped": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		g.stream(fields)
+	},
+	"streamRejected": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		g.stream(fields)
+		fields["errorMessage"] = "unable to connect to wss://example.com/stream"
+	},
+	"transcription": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		fields["transcriptionName"] = "live"
+		fields["transcription"] = map[string]interface{}{
+			"text":       "I would like to check my balance",
+			"confidence": 0.92,
+			"track":      "inbound",
+			"stable":     true,
+		}
+	},
+	"transcriptionAvailable": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.call(fields)
+		g.recording(fields)
+		delete(fields, "channels")
+		fields["transcription"] = map[string]interface{}{
+			"id":            "t-" + g.uuid(),
+			"url":           fmt.Sprintf("%v/recordings/%v/transcription", g.callUrl(), g.recordingId),
+			"status":        "available",
+			"completedTime": format(g.now),
+		}
+	},
+	"transferAnswer": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.transfer(fields)
+	},
+	"transferComplete": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.transfer(fields)
+		// transferComplete is sent to the A leg once the B leg ends
+		g.call(fields)
+		delete(fields, "parentCallId")
+		fields["cause"] = "hangup"
+	},
+	"transferDisconnect": func(g *Generator, fields map[string]interface{}) {
+		g.answered()
+		g.transfer(fields)
+		fields["endTime"] = format(g.now)
+		fields["cause"] = "hangup"
+	},
+}
+
+// answered records the answer time of the call the first time it is called
+func (g *Generator) answered() {
+	if g.answerTime.IsZero() {
+		g.answerTime = g.now
+	}
+}
+
+// call adds the fields shared by call callbacks
+func (g *Generator) call(fields map[string]interface{}) {
+	fields["accountId"] = g.options.accountId
+	fields["applicationId"] = g.options.applicationId
+	fields["from"] = g.options.from
+	fields["to"] = g.options.to
+	fields["direction"] = g.options.direction
+	fields["callId"] = g.callId
+	fields["callUrl"] = g.callUrl()
+	fields["startTime"] = format(g.options.start)
+	fields["tag"] = g.options.tag
+	if !g.answerTime.IsZero() {
+		fields["answerTime"] = format(g.answerTime)
+	}
+}
+
+// conference adds the fields shared by conference callbacks
+func (g *Generator) conference(fields map[string]interface{}) {
+	fields["conferenceId"] = g.conferenceId
+	fields["name"] = "fixture-conference"
+	fields["tag"] = g.options.tag
+}
+
+// member adds the call of a conference member
+func (g *Generator) member(fields map[string]interface{}) {
+	fields["callId"] = g.callId
+	fields["from"] = g.options.from
+	fields["to"] = g.options.to
+}
+
+// recording adds the fields of the current recording, starting one if needed
+func (g *Generator) recording(fields map[string]interface{}) {
+	if g.recordingId == "" {
+		g.recordingId = "r-" + g.hex(8) + "-" + g.uuid()
+		g.recordStart = g.now.Add(-g.options.step)
+		g.recordEnd = g.now
+	}
+	fields["recordingId"] = g.recordingId
+	fields["mediaUrl"] = fmt.Sprintf("%v/recordings/%v/media", g.callUrl(), g.recordingId)
+	fields["startTime"] = format(g.recordStart)
+	fields["endTime"] = format(g.recordEnd)
+	fields["duration"] = fmt.Sprintf("PT%vS", g.recordEnd.Sub(g.recordStart).Seconds())
+	fields["channels"] = 1
+	fields["fileFormat"] = "wav"
+}
+
+// transfer adds the fields of the B leg of a transfer, starting one if needed
+func (g *Generator) transfer(fields map[string]interface{}) {
+	if g.transferId == "" {
+		g.transferId = "c-" + g.hex(8) + "-" + g.uuid()
+		g.transferTime = g.now
+	}
+	fields["accountId"] = g.options.accountId
+	fields["applicationId"] = g.options.applicationId
+	fields["from"] = g.options.from
+	fields["to"] = "+15555550111"
+	fields["direction"] = "outbound"
+	fields["callId"] = g.transferId
+	fields["callUrl"] = fmt.Sprintf("https://voice.bandwidth.com/api/v2/accounts/%v/calls/%v", g.options.accountId, g.transferId)
+	fields["parentCallId"] = g.callId
+	fields["startTime"] = format(g.transferTime)
+	fields["answerTime"] = format(g.transferTime)
+	fields["tag"] = g.options.tag
+	fields["transferCallerId"] = g.options.from
+	fields["transferTo"] = "+15555550111"
+}
+
+// stream adds the parameters of the current media stream, starting one if needed
+func (g *Generator) stream(fields map[string]interface{}) {
+	if g.streamId == "" {
+		g.streamId = "s-" + g.uuid()
+	}
+	fields["streamParams"] = map[string]interface{}{
+		"streamId":    g.streamId,
+		"name":        "fixture-stream",
+		"tracks":      "inbound",
+		"destination": "wss://example.com/stream",
+	}
+}
+
+func (g *Generator) callUrl() string {
+	return fmt.Sprintf("https://voice.bandwidth.com/api/v2/accounts/%v/calls/%v", g.options.accountId, g.callId)
+}
+
+func (g *Generator) digit() string {
+	return fmt.Sprintf("%v", 1+g.rand.Intn(9))
+}
+
+func (g *Generator) hex(n int) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digits[g.rand.Intn(len(digits))]
+	}
+	return string(b)
+}
+
+func (g *Generator) uuid() string {
+	return g.hex(8) + "-" + g.hex(4) + "-" + g.hex(4) + "-" + g.hex(4) + "-" + g.hex(12)
+}
+
+func format(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
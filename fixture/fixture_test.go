@@ -0,0 +1,93 @@
+package fixture
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/savaki/bandwidth"
+)
+
+func TestLifecycle(t *testing.T) {
+	g := New(WithSeed(42), WithTag("order-1234"))
+
+	events, err := g.Lifecycle()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(events), len(CallLifecycle); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	for i, event := range events {
+		if got, want := event.GetEventType(), CallLifecycle[i]; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := event.GetCallId(), g.CallId(); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if i > 0 && !event.GetEventTime().After(events[i-1].GetEventTime()) {
+			t.Fatalf("got %v at or before %v; want ordered times", event.GetEventTime(), events[i-1].GetEventTime())
+		}
+		if event.GetEventType() != "initiate" {
+			if got, want := event.GetTag(), "order-1234"; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		}
+	}
+
+	record := events[4].(*bandwidth.RecordCompleteEvent)
+	available := events[6].(*bandwidth.RecordingAvailableEvent)
+	if got, want := available.RecordingId, record.RecordingId; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	for _, eventType := range EventTypes() {
+		t.Run(eventType, func(t *testing.T) {
+			a, err := New(WithSeed(7)).JSON(eventType)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			b, _ := New(WithSeed(7)).JSON(eventType)
+			if !bytes.Equal(a, b) {
+				t.Fatalf("got %s; want %s", a, b)
+			}
+
+			event, err := bandwidth.ParseEvent(a)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if _, ok := event.(*bandwidth.RawEvent); ok {
+				t.Fatalf("got *RawEvent; want typed event")
+			}
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	g := New()
+	events, err := g.Events("answer", "transferAnswer", "transferDisconnect", "transferComplete")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	answer := events[1].(*bandwidth.TransferAnswerEvent)
+	disconnect := events[2].(*bandwidth.TransferDisconnectEvent)
+	complete := events[3].(*bandwidth.TransferCompleteEvent)
+	if got, want := answer.ParentCallId, g.CallId(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := disconnect.CallId, answer.CallId; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := complete.CallId, g.CallId(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	if _, err := New().Event("nope"); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
@@ -0,0 +1,149 @@
+// Package flowgraph renders call flows, such as an ivr.Flow or a crawl by the simulator, as
+// Graphviz DOT or Mermaid diagrams.  Nodes are the steps of a call labelled with their prompts;
+// edges are labelled with the digits or events that lead from one step to the next.
+package flowgraph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Kind of node
+type Kind string
+
+const (
+	KindStep Kind = "step" // KindStep - plays prompts and may collect digits
+	KindEnd  Kind = "end"  // KindEnd - ends the flow e.g. a transfer or hangup
+)
+
+// Node is a step of a call flow
+type Node struct {
+	Id    string
+	Label string // Label - prompts of the step; lines are separated by \n
+	Kind  Kind
+}
+
+// Edge leads from one step to another
+type Edge struct {
+	From  string
+	To    string
+	Label string // Label - digits or event e.g. 1, timeout, redirect
+}
+
+// Graph is a call flow
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Node returns the node with the given id
+func (g *Graph) Node(id string) (Node, bool) {
+	for _, n := range g.Nodes {
+		if n.Id == id {
+			return n, true
+		}
+	}
+	return Node{}, false
+}
+
+// AddNode adds n unless a node with the same id already exists
+func (g *Graph) AddNode(n Node) {
+	if _, ok := g.Node(n.Id); ok {
+		return
+	}
+	if n.Kind == "" {
+		n.Kind = KindStep
+	}
+	g.Nodes = append(g.Nodes, n)
+}
+
+// AddEdge adds e.  Edges between the same nodes are combined and their labels joined e.g. 1, 2
+func (g *Graph) AddEdge(e Edge) {
+	for i, existing := range g.Edges {
+		if existing.From != e.From || existing.To != e.To {
+			continue
+		}
+		for _, label := range strings.Split(existing.Label, ", ") {
+			if label == e.Label {
+				return
+			}
+		}
+		if e.Label != "" {
+			if existing.Label != "" {
+				e.Label = existing.Label + ", " + e.Label
+			}
+			g.Edges[i].Label = e.Label
+		}
+		return
+	}
+	g.Edges = append(g.Edges, e)
+}
+
+// WriteDOT writes the graph in Graphviz DOT format
+func (g Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph flow {\n")
+	bw.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		shape := "box"
+		if n.Kind == KindEnd {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(bw, "  %v [label=%v, shape=%v];\n", dotQuote(n.Id), dotQuote(n.Label), shape)
+	}
+	for _, e := range g.Edges {
+		if e.Label == "" {
+			fmt.Fprintf(bw, "  %v -> %v;\n", dotQuote(e.From), dotQuote(e.To))
+			continue
+		}
+		fmt.Fprintf(bw, "  %v -> %v [label=%v];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Label))
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart
+func (g Graph) WriteMermaid(w io.Writer) error {
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.Id] = fmt.Sprintf("n%v", i)
+	}
+	id := func(s string) string {
+		if v, ok := ids[s]; ok {
+			return v
+		}
+		v := fmt.Sprintf("n%v", len(ids))
+		ids[s] = v
+		return v
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("flowchart TD\n")
+	for _, n := range g.Nodes {
+		if n.Kind == KindEnd {
+			fmt.Fprintf(bw, "  %v([\"%v\"])\n", id(n.Id), mermaidEscape(n.Label))
+		} else {
+			fmt.Fprintf(bw, "  %v[\"%v\"]\n", id(n.Id), mermaidEscape(n.Label))
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Label == "" {
+			fmt.Fprintf(bw, "  %v --> %v\n", id(e.From), id(e.To))
+			continue
+		}
+		fmt.Fprintf(bw, "  %v -->|\"%v\"| %v\n", id(e.From), mermaidEscape(e.Label), id(e.To))
+	}
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "|", "#124;", "<", "#lt;", ">", "#gt;", "\n", "<br/>")
+	return r.Replace(s)
+}
@@ -0,0 +1,67 @@
+package flowgraph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testGraph() Graph {
+	var g Graph
+	g.AddNode(Node{Id: "main", Label: "main\nPress 1 for \"sales\""})
+	g.AddNode(Node{Id: "hangup", Label: "Hangup", Kind: KindEnd})
+	g.AddNode(Node{Id: "main", Label: "ignored"})
+	g.AddEdge(Edge{From: "main", To: "hangup", Label: "1"})
+	g.AddEdge(Edge{From: "main", To: "hangup", Label: "2"})
+	g.AddEdge(Edge{From: "main", To: "hangup", Label: "1"})
+	g.AddEdge(Edge{From: "main", To: "main", Label: "timeout"})
+	return g
+}
+
+func TestAdd(t *testing.T) {
+	g := testGraph()
+	if got, want := len(g.Nodes), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(g.Edges), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := g.Edges[0].Label, "1, 2"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := testGraph().WriteDOT(buf); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := `digraph flow {
+  node [shape=box];
+  "main" [label="main\nPress 1 for \"sales\"", shape=box];
+  "hangup" [label="Hangup", shape=ellipse];
+  "main" -> "hangup" [label="1, 2"];
+  "main" -> "main" [label="timeout"];
+}
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := testGraph().WriteMermaid(buf); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := `flowchart TD
+  n0["main<br/>Press 1 for #quot;sales#quot;"]
+  n1(["Hangup"])
+  n0 -->|"1, 2"| n1
+  n0 -->|"timeout"| n0
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
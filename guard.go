@@ -0,0 +1,164 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+var (
+	// ErrCallbackTimeout is reported when a handler does not respond before the guard's deadline
+	ErrCallbackTimeout = errors.New("callback timeout")
+	// ErrCallbackPanic is reported when a handler panics
+	ErrCallbackPanic = errors.New("callback panic")
+)
+
+type guardOptions struct {
+	timeout  time.Duration
+	margin   time.Duration
+	fallback bxml.Response
+	recover  bxml.Response
+	onError  func(req *http.Request, err error)
+}
+
+// GuardOption configures GuardCallback
+type GuardOption func(*guardOptions)
+
+// WithCallbackTimeout sets the callback timeout of the calls handled, as set by CallbackTimeout
+// on CreateCallInput; defaults to Bandwidth's default of 15s
+func WithCallbackTimeout(d time.Duration) GuardOption {
+	return func(o *guardOptions) {
+		o.timeout = d
+	}
+}
+
+// WithTimeoutMargin sets how long before the callback timeout the handler's context expires and
+// the fallback is sent; defaults to 1s
+func WithTimeoutMargin(d time.Duration) GuardOption {
+	return func(o *guardOptions) {
+		o.margin = d
+	}
+}
+
+// WithTimeoutResponse sets the BXML sent when the handler is too slow e.g. a <Redirect> to a
+// holding url.  Defaults to an apology and <Hangup>.
+func WithTimeoutResponse(response bxml.Response) GuardOption {
+	return func(o *guardOptions) {
+		o.fallback = response
+	}
+}
+
+// WithPanicResponse sets the BXML sent when the handler panics; defaults to the timeout response
+func WithPanicResponse(response bxml.Response) GuardOption {
+	return func(o *guardOptions) {
+		o.recover = response
+	}
+}
+
+// WithGuardErrorHandler calls fn when the handler times out, with ErrCallbackTimeout, or panics,
+// with an error wrapping ErrCallbackPanic
+func WithGuardErrorHandler(fn func(req *http.Request, err error)) GuardOption {
+	return func(o *guardOptions) {
+		o.onError = fn
+	}
+}
+
+// GuardCallback returns a handler that ensures Bandwidth receives BXML before its callback times
+// out.  The request context of next expires the margin before the callback timeout; if next has
+// not responded by then, the timeout response is sent and anything next writes afterwards is
+// discarded.  If next panics, the panic response is sent with a 200 rather than a 500.
+//
+//	h := bandwidth.GuardCallback(router,
+//		bandwidth.WithCallbackTimeout(10*time.Second),
+//		bandwidth.WithTimeoutResponse(bxml.NewResponse(bxml.Redirect{RedirectUrl: "/hold"})),
+//	)
+func GuardCallback(next http.Handler, opts ...GuardOption) http.Handler {
+	options := guardOptions{
+		timeout:  15 * time.Second,
+		margin:   time.Second,
+		fallback: bxml.NewResponse().Speak("We're sorry, we are unable to complete your call at this time. Goodbye.").Hangup(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.recover.Len() == 0 {
+		options.recover = options.fallback
+	}
+
+	deadline := options.timeout - options.margin
+	if deadline <= 0 {
+		deadline = options.timeout
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), deadline)
+		defer cancel()
+
+		buf := &bufferedWriter{header: http.Header{}}
+		done := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				done <- recover()
+			}()
+			next.ServeHTTP(buf, req.WithContext(ctx))
+		}()
+
+		select {
+		case r := <-done:
+			if r != nil {
+				if options.onError != nil {
+					options.onError(req, fmt.Errorf("%w: %v", ErrCallbackPanic, r))
+				}
+				writeResponse(w, options.recover)
+				return
+			}
+			buf.copyTo(w)
+
+		case <-ctx.Done():
+			if options.onError != nil {
+				options.onError(req, ErrCallbackTimeout)
+			}
+			writeResponse(w, options.fallback)
+		}
+	})
+}
+
+// bufferedWriter holds the response of a handler until the guard decides whether to send it
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedWriter) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+func (b *bufferedWriter) copyTo(w http.ResponseWriter) {
+	for name, values := range b.header {
+		w.Header()[name] = values
+	}
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,98 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+func TestGuardCallback(t *testing.T) {
+	hold := bxml.NewResponse(bxml.Redirect{RedirectUrl: "/hold"})
+
+	testCases := map[string]struct {
+		Handler http.HandlerFunc
+		Status  int
+		Body    string
+		Err     error
+	}{
+		"fast": {
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				bxml.NewResponse().Speak("hello").ServeHTTP(w, req)
+			},
+			Status: http.StatusOK,
+			Body:   "hello",
+		},
+		"error status": {
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				http.Error(w, "bad request", http.StatusBadRequest)
+			},
+			Status: http.StatusBadRequest,
+			Body:   "bad request",
+		},
+		"slow": {
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				<-req.Context().Done()
+				bxml.NewResponse().Speak("too late").ServeHTTP(w, req)
+			},
+			Status: http.StatusOK,
+			Body:   "/hold",
+			Err:    ErrCallbackTimeout,
+		},
+		"panic": {
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				panic("boom")
+			},
+			Status: http.StatusOK,
+			Body:   "Hangup",
+			Err:    ErrCallbackPanic,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var got error
+			h := GuardCallback(tc.Handler,
+				WithCallbackTimeout(60*time.Millisecond),
+				WithTimeoutMargin(20*time.Millisecond),
+				WithTimeoutResponse(hold),
+				WithPanicResponse(bxml.NewResponse().Speak("sorry").Hangup()),
+				WithGuardErrorHandler(func(req *http.Request, err error) { got = err }),
+			)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", nil))
+			if got, want := w.Code, tc.Status; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := w.Body.String(), tc.Body; !strings.Contains(got, want) {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if !errors.Is(got, tc.Err) {
+				t.Fatalf("got %v; want %v", got, tc.Err)
+			}
+		})
+	}
+}
+
+func TestGuardCallbackDeadline(t *testing.T) {
+	var remaining time.Duration
+	router := NewCallbackRouter()
+	router.OnAnswer(func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		deadline, _ := ctx.Deadline()
+		remaining = time.Until(deadline)
+		return bxml.NewResponse().Hangup(), nil
+	})
+
+	h := GuardCallback(router, WithCallbackTimeout(10*time.Second), WithTimeoutMargin(2*time.Second))
+	serveFile(t, h, "testdata/answer.json")
+
+	if remaining <= 7*time.Second || remaining > 8*time.Second {
+		t.Fatalf("got %v; want about 8s", remaining)
+	}
+}
@@ -0,0 +1,300 @@
+package bandwidth
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventKey returns a stable key identifying a callback.  Retries of a callback, including those
+// sent to the fallback url, share the same key.  The key is derived from the event type, account,
+// call, event time, and tag, along with the conference, transfer, and recording ids for events
+// that have them.  Events without an event time fall back to every field of the event.
+func EventKey(event Event) string {
+	fields := []string{
+		event.GetEventType(),
+		event.GetAccountId(),
+		event.GetCallId(),
+		event.GetTag(),
+	}
+	if t := event.GetEventTime(); !t.IsZero() {
+		fields = append(fields, t.UTC().Format(time.RFC3339Nano))
+	} else if data, err := json.Marshal(event); err == nil {
+		fields = append(fields, string(data))
+	}
+	if e, ok := event.(ConferenceEvent); ok {
+		fields = append(fields, e.GetConferenceId())
+	}
+	if e, ok := event.(TransferEvent); ok {
+		fields = append(fields, e.GetParentCallId(), e.GetTransferCallerId(), e.GetTransferTo())
+	}
+	if e, ok := event.(RecordingEvent); ok {
+		fields = append(fields, e.GetRecordingId())
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// SeenStore records the callbacks that have been processed along with the BXML returned.  A key is
+// reserved while its callback is handled so that concurrent duplicates are not processed twice.
+type SeenStore interface {
+	// Reserve claims key for processing.  If key has been stored, its response is returned and ok
+	// is true.  If key is reserved by another caller, Reserve waits until it is stored or released,
+	// or ctx is done.
+	Reserve(ctx context.Context, key string) (response []byte, ok bool, err error)
+	// Store records that key was processed and returned response, and ends its reservation;
+	// response may be empty
+	Store(ctx context.Context, key string, response []byte) error
+	// Release ends the reservation of key without storing a response so it may be processed again
+	Release(ctx context.Context, key string) error
+}
+
+// WithSeenStore skips callbacks already processed, as determined by EventKey, and replays the
+// response stored for them instead of calling the handler again.  Duplicates that arrive while
+// the first is still being handled wait for its response.  Responses are stored only when the
+// handler succeeds, so failed callbacks are processed again when retried.
+func WithSeenStore(store SeenStore) RouterOption {
+	return func(o *routerOptions) {
+		o.seen = store
+	}
+}
+
+// reservations holds the keys being processed; each channel is closed once its key is stored or
+// released.  Callers must hold the lock of the store.
+type reservations map[string]chan struct{}
+
+// reserve claims key, or returns the channel to wait on if key is already reserved
+func (r reservations) reserve(key string) (wait chan struct{}, ok bool) {
+	if wait, found := r[key]; found {
+		return wait, false
+	}
+	r[key] = make(chan struct{})
+	return nil, true
+}
+
+// release ends the reservation of key, if any, waking those waiting on it
+func (r reservations) release(key string) {
+	if wait, ok := r[key]; ok {
+		close(wait)
+		delete(r, key)
+	}
+}
+
+// reserve implements SeenStore.Reserve for a store whose lock is mu, using seen to find the
+// responses stored
+func reserve(ctx context.Context, mu *sync.Mutex, pending reservations, key string, seen func() ([]byte, bool)) ([]byte, bool, error) {
+	for {
+		mu.Lock()
+		if response, ok := seen(); ok {
+			mu.Unlock()
+			return response, true, nil
+		}
+		wait, ok := pending.reserve(key)
+		mu.Unlock()
+		if ok {
+			return nil, false, nil
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+type memoryEntry struct {
+	key      string
+	response []byte
+}
+
+// MemoryStore is an in memory SeenStore that holds the most recently used keys.  MemoryStore is
+// safe for concurrent use.
+type MemoryStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // order of entries from most to least recently used
+	pending reservations
+}
+
+// NewMemoryStore returns a MemoryStore holding up to capacity keys
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		pending:  reservations{},
+	}
+}
+
+// Seen returns the response stored for key, if any
+func (m *MemoryStore) Seen(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	response, ok := m.seen(key)
+	return response, ok, nil
+}
+
+func (m *MemoryStore) seen(key string) ([]byte, bool) {
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryEntry).response, true
+}
+
+// Reserve implements SeenStore
+func (m *MemoryStore) Reserve(ctx context.Context, key string) ([]byte, bool, error) {
+	return reserve(ctx, &m.mu, m.pending, key, func() ([]byte, bool) { return m.seen(key) })
+}
+
+// Release implements SeenStore
+func (m *MemoryStore) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pending.release(key)
+	return nil
+}
+
+// Store implements SeenStore
+func (m *MemoryStore) Store(ctx context.Context, key string, response []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.pending.release(key)
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryEntry).response = response
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, response: response})
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// FileStore is a SeenStore that appends each key and response to a file so duplicates are
+// detected across restarts.  Every key is held in memory.  FileStore is safe for concurrent use.
+type FileStore struct {
+	mu       sync.Mutex
+	file     *os.File
+	seen     map[string][]byte
+	pending  reservations
+	encoder  *json.Encoder
+	filename string
+}
+
+type fileEntry struct {
+	Key      string `json:"key"`
+	Response []byte `json:"response,omitempty"`
+}
+
+// OpenFileStore opens, or creates, the FileStore at filename
+func OpenFileStore(filename string) (*FileStore, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open seen store, %v: %w", filename, err)
+	}
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to read seen store, %v: %w", filename, err)
+	}
+
+	// a partially written final line, left by a crash, is removed so the next entry starts on a
+	// line of its own
+	if n := bytes.LastIndexByte(data, '\n') + 1; n < len(data) {
+		if err := f.Truncate(int64(n)); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("unable to repair seen store, %v: %w", filename, err)
+		}
+		data = data[:n]
+	}
+
+	seen := map[string][]byte{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var entry fileEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		seen[entry.Key] = entry.Response
+	}
+
+	return &FileStore{
+		file:     f,
+		seen:     seen,
+		pending:  reservations{},
+		encoder:  json.NewEncoder(f),
+		filename: filename,
+	}, nil
+}
+
+// Seen returns the response stored for key, if any
+func (f *FileStore) Seen(ctx context.Context, key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response, ok := f.seen[key]
+	return response, ok, nil
+}
+
+// Reserve implements SeenStore
+func (f *FileStore) Reserve(ctx context.Context, key string) ([]byte, bool, error) {
+	return reserve(ctx, &f.mu, f.pending, key, func() ([]byte, bool) {
+		response, ok := f.seen[key]
+		return response, ok
+	})
+}
+
+// Release implements SeenStore
+func (f *FileStore) Release(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending.release(key)
+	return nil
+}
+
+// Store implements SeenStore.  The reservation of key ends even if the response can't be written.
+func (f *FileStore) Store(ctx context.Context, key string, response []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defer f.pending.release(key)
+
+	if err := f.encoder.Encode(fileEntry{Key: key, Response: response}); err != nil {
+		return fmt.Errorf("unable to write seen store, %v: %w", f.filename, err)
+	}
+	f.seen[key] = response
+	return nil
+}
+
+// Close closes the underlying file
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
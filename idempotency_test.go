@@ -0,0 +1,235 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+func TestEventKey(t *testing.T) {
+	a := &DisconnectEvent{EventType: "disconnect", CallId: "c-1", EventTime: "2020-01-02T03:04:05.000Z"}
+	b := &DisconnectEvent{EventType: "disconnect", CallId: "c-1", EventTime: "2020-01-02T03:04:05Z", Cause: "hangup"}
+	c := &DisconnectEvent{EventType: "disconnect", CallId: "c-2", EventTime: "2020-01-02T03:04:05.000Z"}
+
+	if got, want := EventKey(a), EventKey(b); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := EventKey(a), EventKey(c); got == want {
+		t.Fatalf("got %v; want different keys", got)
+	}
+
+	r1 := &RecordingAvailableEvent{EventType: "recordingAvailable", CallId: "c-1", EventTime: "2020-01-02T03:04:05Z", RecordingId: "r-1"}
+	r2 := &RecordingAvailableEvent{EventType: "recordingAvailable", CallId: "c-1", EventTime: "2020-01-02T03:04:05Z", RecordingId: "r-2"}
+	if got, want := EventKey(r1), EventKey(r2); got == want {
+		t.Fatalf("got %v; want different keys", got)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	store.Store(ctx, "a", []byte("A"))
+	store.Store(ctx, "b", nil)
+	store.Seen(ctx, "a") // a is now more recently used than b
+	store.Store(ctx, "c", nil)
+
+	if _, ok, _ := store.Seen(ctx, "b"); ok {
+		t.Fatalf("got true; want b evicted")
+	}
+	got, ok, _ := store.Seen(ctx, "a")
+	if !ok || string(got) != "A" {
+		t.Fatalf("got %v %v; want A true", string(got), ok)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "seen.jsonl")
+
+	store, err := OpenFileStore(filename)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := store.Store(ctx, "a", []byte("<Response/>")); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	store.Close()
+
+	store, err = OpenFileStore(filename)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer store.Close()
+
+	got, ok, err := store.Seen(ctx, "a")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !ok || string(got) != "<Response/>" {
+		t.Fatalf("got %v %v; want <Response/> true", string(got), ok)
+	}
+}
+
+func TestFileStoreTornLine(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "seen.jsonl")
+
+	// the process crashed while writing b
+	torn := `{"key":"a","response":"QQ=="}` + "\n" + `{"key":"b","resp`
+	if err := ioutil.WriteFile(filename, []byte(torn), 0600); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	store, err := OpenFileStore(filename)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if _, ok, _ := store.Seen(ctx, "b"); ok {
+		t.Fatalf("got true; want torn entry ignored")
+	}
+	if err := store.Store(ctx, "c", []byte("C")); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	store.Close()
+
+	store, err = OpenFileStore(filename)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer store.Close()
+
+	for key, want := range map[string]string{"a": "A", "c": "C"} {
+		got, ok, err := store.Seen(ctx, key)
+		if err != nil || !ok || string(got) != want {
+			t.Fatalf("got %v %v %v; want %v true nil", string(got), ok, err, want)
+		}
+	}
+}
+
+func TestCallbackRouterSeenStore(t *testing.T) {
+	var calls int
+	router := NewCallbackRouter(WithSeenStore(NewMemoryStore(10)))
+	router.OnAnswer(func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		calls++
+		return bxml.NewResponse().Speak(fmt.Sprintf("call %v", calls)), nil
+	})
+
+	first := serveFile(t, router, "testdata/answer.json")
+	second := serveFile(t, router, "testdata/answer.json")
+
+	if got, want := calls, 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := second.Body.String(), first.Body.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := second.Header().Get("Content-Type"), bxml.ContentType; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestCallbackRouterSeenStoreConcurrent(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/answer.json")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var (
+		calls   int32
+		started = make(chan struct{})
+		finish  = make(chan struct{})
+	)
+	router := NewCallbackRouter(WithSeenStore(NewMemoryStore(10)))
+	router.OnAnswer(func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-finish
+		}
+		return bxml.NewResponse().Speak("hello"), nil
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", bytes.NewReader(data)))
+		return w
+	}
+
+	// the duplicate arrives while the first is still being handled, e.g. a retry of a slow callback
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- serve() }()
+	<-started
+	second := make(chan *httptest.ResponseRecorder)
+	go func() { second <- serve() }()
+
+	time.Sleep(10 * time.Millisecond)
+	close(finish)
+
+	a, b := <-first, <-second
+	if got, want := atomic.LoadInt32(&calls), int32(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := b.Body.String(), a.Body.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestCallbackRouterSeenStoreReleasesOnError(t *testing.T) {
+	var calls int
+	router := NewCallbackRouter(WithSeenStore(NewMemoryStore(10)))
+	router.OnAnswer(func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		calls++
+		if calls == 1 {
+			return bxml.Response{}, fmt.Errorf("boom")
+		}
+		return bxml.NewResponse().Speak("hello"), nil
+	})
+
+	if got, want := serveFile(t, router, "testdata/answer.json").Code, http.StatusInternalServerError; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := serveFile(t, router, "testdata/answer.json").Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := calls, 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestMemoryStoreReserve(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	if _, ok, err := store.Reserve(ctx, "a"); ok || err != nil {
+		t.Fatalf("got %v %v; want false nil", ok, err)
+	}
+
+	// a second reservation waits until the first is stored
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := store.Reserve(timeout, "a"); err != context.DeadlineExceeded {
+		t.Fatalf("got %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	store.Store(ctx, "a", []byte("A"))
+	got, ok, err := store.Reserve(ctx, "a")
+	if err != nil || !ok || string(got) != "A" {
+		t.Fatalf("got %v %v %v; want A true nil", string(got), ok, err)
+	}
+
+	// a released key may be reserved again
+	store.Reserve(ctx, "b")
+	store.Release(ctx, "b")
+	if _, ok, err := store.Reserve(ctx, "b"); ok || err != nil {
+		t.Fatalf("got %v %v; want false nil", ok, err)
+	}
+}
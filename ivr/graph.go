@@ -0,0 +1,64 @@
+package ivr
+
+import (
+	"strings"
+
+	"github.com/savaki/bandwidth/flowgraph"
+)
+
+// Graph returns the phone tree with a node for each menu, labelled with its prompts, and an end
+// node for each transfer, redirect, and hangup.  Edges are labelled with the digits pressed and
+// any message spoken on the way.
+func (f Flow) Graph() flowgraph.Graph {
+	var g flowgraph.Graph
+	for _, m := range f.Menus {
+		lines := []string{m.Name}
+		for _, p := range m.Prompts {
+			if p.Play != "" {
+				lines = append(lines, "play "+p.Play)
+			} else {
+				lines = append(lines, p.Say)
+			}
+		}
+		g.AddNode(flowgraph.Node{Id: menuId(m.Name), Label: strings.Join(lines, "\n")})
+	}
+
+	for _, m := range f.Menus {
+		for _, r := range m.Routes {
+			addAction(&g, menuId(m.Name), r.Digits, r.Action)
+		}
+
+		failure := Action{Hangup: true}
+		if m.OnFailure != nil {
+			failure = *m.OnFailure
+		}
+		addAction(&g, menuId(m.Name), "no match", failure)
+	}
+	return g
+}
+
+func addAction(g *flowgraph.Graph, from, label string, a Action) {
+	if a.Say != "" {
+		label += "\n" + a.Say
+	}
+
+	var to string
+	switch {
+	case a.Goto != "":
+		to = menuId(a.Goto)
+	case a.Transfer != "":
+		to = "transfer:" + a.Transfer
+		g.AddNode(flowgraph.Node{Id: to, Label: "Transfer " + a.Transfer, Kind: flowgraph.KindEnd})
+	case a.Redirect != "":
+		to = "redirect:" + a.Redirect
+		g.AddNode(flowgraph.Node{Id: to, Label: "Redirect " + a.Redirect, Kind: flowgraph.KindEnd})
+	default:
+		to = "hangup"
+		g.AddNode(flowgraph.Node{Id: to, Label: "Hangup", Kind: flowgraph.KindEnd})
+	}
+	g.AddEdge(flowgraph.Edge{From: from, To: to, Label: label})
+}
+
+func menuId(name string) string {
+	return "menu:" + name
+}
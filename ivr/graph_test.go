@@ -0,0 +1,37 @@
+package ivr
+
+import (
+	"testing"
+
+	"github.com/savaki/bandwidth/flowgraph"
+)
+
+func TestGraph(t *testing.T) {
+	g := loadMenu(t).Graph()
+
+	if got, want := len(g.Nodes), 6; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	main, _ := g.Node("menu:main")
+	if got, want := main.Label, "main\nPress 1 for sales, 2 for billing, or 0 for an operator"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	want := []flowgraph.Edge{
+		{From: "menu:main", To: "transfer:+18005550001", Label: "1\nConnecting you to sales"},
+		{From: "menu:main", To: "menu:billing", Label: "2"},
+		{From: "menu:main", To: "transfer:sip:operator@example.com", Label: "0"},
+		{From: "menu:main", To: "hangup", Label: "no match\nGoodbye"},
+		{From: "menu:billing", To: "redirect:/pay", Label: "1"},
+		{From: "menu:billing", To: "menu:main", Label: "9"},
+		{From: "menu:billing", To: "hangup", Label: "no match"},
+	}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("got %v; want %v", g.Edges, want)
+	}
+	for i := range want {
+		if g.Edges[i] != want[i] {
+			t.Fatalf("got %v; want %v", g.Edges[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,395 @@
+// Package ivr compiles declarative phone menus into BXML handlers.  Menus, prompts, digit routes,
+// retries, timeouts, and transfers are declared as a Flow, in Go or loaded from JSON or YAML, and
+// compiled into an http.Handler that serves the BXML for each step of the call.
+//
+// The handler is stateless; the menu and attempt of each call are carried in the tag of the
+// <Gather> e.g. ivr:billing:1, so a single handler may be mounted at both the answer and gather
+// urls of an application.  The tag the call had when it entered the ivr is kept after the state,
+// e.g. ivr:billing:1:order-42, and restored when the call leaves the ivr by transfer or redirect.
+// Gather callbacks are sent to the url the request was received on, query included.
+//
+//	flow := ivr.Flow{
+//		Start: "main",
+//		Menus: []ivr.Menu{
+//			{
+//				Name:    "main",
+//				Prompts: []ivr.Prompt{{Say: "Press 1 for sales or 2 for support"}},
+//				Routes: []ivr.Route{
+//					{Digits: "1", Action: ivr.Action{Transfer: "+18005550001"}},
+//					{Digits: "2", Action: ivr.Action{Goto: "support"}},
+//				},
+//			},
+//			...
+//		},
+//	}
+//	handler, err := ivr.Compile(flow)
+package ivr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/savaki/bandwidth"
+	"github.com/savaki/bandwidth/bxml"
+)
+
+const (
+	tagPrefix   = "ivr:"  // tagPrefix identifies tags that carry ivr state
+	maxBodySize = 1 << 20 // maxBodySize limits the size of a callback body
+)
+
+// Flow is a complete phone tree
+type Flow struct {
+	Start string `json:"start" yaml:"start"`                     // Start - name of the first menu
+	Voice string `json:"voice,omitempty" yaml:"voice,omitempty"` // Voice - voice of every SpeakSentence e.g. julie
+	Menus []Menu `json:"menus" yaml:"menus"`
+}
+
+// Prompt is played to the caller; either text to speak or the url of audio to play
+type Prompt struct {
+	Say  string `json:"say,omitempty" yaml:"say,omitempty"`
+	Play string `json:"play,omitempty" yaml:"play,omitempty"`
+}
+
+// Action is what happens once a route is chosen.  Say, if set, is spoken first, then exactly one
+// of Goto, Transfer, Redirect, or Hangup.
+type Action struct {
+	Say      string `json:"say,omitempty" yaml:"say,omitempty"`
+	Goto     string `json:"goto,omitempty" yaml:"goto,omitempty"`         // Goto - name of the next menu
+	Transfer string `json:"transfer,omitempty" yaml:"transfer,omitempty"` // Transfer - phone number or sip uri
+	Redirect string `json:"redirect,omitempty" yaml:"redirect,omitempty"` // Redirect - url of an application handler
+	Hangup   bool   `json:"hangup,omitempty" yaml:"hangup,omitempty"`
+}
+
+// Route chooses an action when the caller presses the given digits
+type Route struct {
+	Digits string `json:"digits" yaml:"digits"`
+	Action `yaml:",inline"`
+}
+
+// Menu collects digits from the caller and routes the call
+type Menu struct {
+	Name              string   `json:"name" yaml:"name"`
+	Prompts           []Prompt `json:"prompts" yaml:"prompts"`
+	MaxDigits         int      `json:"maxDigits,omitempty" yaml:"maxDigits,omitempty"`                 // MaxDigits - defaults to 1
+	TerminatingDigits string   `json:"terminatingDigits,omitempty" yaml:"terminatingDigits,omitempty"` // TerminatingDigits - e.g. #
+	Timeout           int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`                     // Timeout - seconds to wait for the first digit
+	Retries           int      `json:"retries,omitempty" yaml:"retries,omitempty"`                     // Retries - times the menu is repeated after invalid input or a timeout
+	Invalid           *Prompt  `json:"invalid,omitempty" yaml:"invalid,omitempty"`                     // Invalid - played when digits match no route
+	NoInput           *Prompt  `json:"noInput,omitempty" yaml:"noInput,omitempty"`                     // NoInput - played when the caller presses nothing
+	Routes            []Route  `json:"routes" yaml:"routes"`
+	OnFailure         *Action  `json:"onFailure,omitempty" yaml:"onFailure,omitempty"` // OnFailure - once retries are exhausted; defaults to hanging up
+}
+
+// Load reads a Flow encoded as JSON
+func Load(r io.Reader) (Flow, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Flow{}, fmt.Errorf("unable to read ivr flow: %w", err)
+	}
+	return LoadFunc(data, json.Unmarshal)
+}
+
+// LoadFunc decodes a Flow using unmarshal, such as yaml.Unmarshal, so formats other than JSON may
+// be used without this package depending on them
+func LoadFunc(data []byte, unmarshal func([]byte, interface{}) error) (Flow, error) {
+	var flow Flow
+	if err := unmarshal(data, &flow); err != nil {
+		return Flow{}, fmt.Errorf("unable to decode ivr flow: %w", err)
+	}
+	return flow, nil
+}
+
+// Validate reports every menu that can't be compiled; missing menus, duplicate routes, and
+// actions that don't do exactly one thing
+func (f Flow) Validate() error {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	menus := map[string]bool{}
+	for _, m := range f.Menus {
+		switch {
+		case m.Name == "":
+			report("menu name is required")
+		case strings.Contains(m.Name, ":"):
+			report("menu, %v, name may not contain :", m.Name)
+		case menus[m.Name]:
+			report("menu, %v, is declared more than once", m.Name)
+		}
+		menus[m.Name] = true
+	}
+	if !menus[f.Start] {
+		report("start menu, %q, not found", f.Start)
+	}
+
+	checkAction := func(where string, a Action) {
+		var n int
+		for _, set := range []bool{a.Goto != "", a.Transfer != "", a.Redirect != "", a.Hangup} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			report("%v must have exactly one of goto, transfer, redirect, or hangup", where)
+		}
+		if a.Goto != "" && !menus[a.Goto] {
+			report("%v goes to unknown menu, %q", where, a.Goto)
+		}
+	}
+
+	for _, m := range f.Menus {
+		if len(m.Prompts) == 0 {
+			report("menu, %v, has no prompts", m.Name)
+		}
+		for _, p := range m.Prompts {
+			if (p.Say == "") == (p.Play == "") {
+				report("menu, %v, prompts must have exactly one of say or play", m.Name)
+			}
+		}
+		if m.Retries < 0 {
+			report("menu, %v, retries may not be negative", m.Name)
+		}
+
+		digits := map[string]bool{}
+		for _, r := range m.Routes {
+			where := fmt.Sprintf("menu, %v, route %q", m.Name, r.Digits)
+			switch {
+			case r.Digits == "":
+				report("menu, %v, route digits are required", m.Name)
+			case strings.Trim(r.Digits, "0123456789*#") != "":
+				report("%v may only contain 0-9, *, or #", where)
+			case digits[r.Digits]:
+				report("%v is declared more than once", where)
+			case len(r.Digits) > m.maxDigits():
+				report("%v is longer than maxDigits, %v", where, m.maxDigits())
+			}
+			digits[r.Digits] = true
+			checkAction(where, r.Action)
+		}
+		if m.OnFailure != nil {
+			checkAction(fmt.Sprintf("menu, %v, onFailure", m.Name), *m.OnFailure)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid ivr flow: %v", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (m Menu) maxDigits() int {
+	if m.MaxDigits == 0 {
+		return 1
+	}
+	return m.MaxDigits
+}
+
+// Handler serves the BXML of a compiled Flow
+type Handler struct {
+	flow  Flow
+	menus map[string]Menu
+}
+
+// Compile validates flow and returns a Handler for it
+func Compile(flow Flow) (*Handler, error) {
+	if err := flow.Validate(); err != nil {
+		return nil, err
+	}
+
+	menus := map[string]Menu{}
+	for _, m := range flow.Menus {
+		menus[m.Name] = m
+	}
+	return &Handler{flow: flow, menus: menus}, nil
+}
+
+// ServeHTTP responds to callbacks.  Answer, initiate, and redirect callbacks play the start menu,
+// or the menu named in the tag of a redirect; gather callbacks route the digits pressed.  Other
+// callbacks receive an empty response.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event, err := bandwidth.ParseEvent(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// RequestURI is the url as received, before any prefix was stripped, so signed urls still verify
+	url := req.RequestURI
+	if url == "" {
+		url = req.URL.RequestURI()
+	}
+	cb := callback{url: url, tag: event.GetTag()}
+
+	var response bxml.Response
+	switch e := event.(type) {
+	case *bandwidth.AnswerEvent, *bandwidth.InitiateEvent:
+		response, err = h.menu(cb, h.flow.Start, 0, nil)
+	case *bandwidth.RedirectEvent:
+		name, attempt, tag, ok := h.parseTag(e.Tag)
+		if !ok {
+			name, attempt = h.flow.Start, 0
+		}
+		cb.tag = tag
+		response, err = h.menu(cb, name, attempt, nil)
+	case *bandwidth.GatherEvent:
+		response, err = h.gather(cb, e)
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.ServeHTTP(w, req)
+}
+
+// callback is where gather callbacks are sent and the application's tag of the call
+type callback struct {
+	url string
+	tag string
+}
+
+// gather routes the digits of a GatherEvent
+func (h *Handler) gather(cb callback, event *bandwidth.GatherEvent) (bxml.Response, error) {
+	name, attempt, tag, ok := h.parseTag(event.Tag)
+	if !ok {
+		// state was lost; start again
+		return h.menu(cb, h.flow.Start, 0, nil)
+	}
+	cb.tag = tag
+
+	menu := h.menus[name]
+	for _, route := range menu.Routes {
+		if route.Digits == event.Digits {
+			return h.action(cb, route.Action)
+		}
+	}
+
+	if attempt >= menu.Retries {
+		if menu.OnFailure != nil {
+			return h.action(cb, *menu.OnFailure)
+		}
+		return bxml.NewResponse().Hangup(), nil
+	}
+
+	retry := menu.Invalid
+	if event.Digits == "" {
+		retry = menu.NoInput
+	}
+	return h.menu(cb, name, attempt+1, retry)
+}
+
+// action returns the BXML of an action
+func (h *Handler) action(cb callback, a Action) (bxml.Response, error) {
+	response := bxml.NewResponse()
+	if a.Say != "" {
+		response = response.Append(h.say(a.Say))
+	}
+
+	switch {
+	case a.Goto != "":
+		menu, err := h.menu(cb, a.Goto, 0, nil)
+		if err != nil {
+			return bxml.Response{}, err
+		}
+		return response.Append(menu.Verbs()...), nil
+	case a.Transfer != "":
+		return response.Tag(cb.tag).Transfer(bxml.Transfer{}, a.Transfer), nil
+	case a.Redirect != "":
+		return response.Tag(cb.tag).Redirect(a.Redirect), nil
+	default:
+		return response.Hangup(), nil
+	}
+}
+
+// menu returns the BXML of a menu, optionally preceded by a prompt explaining why it is repeated
+func (h *Handler) menu(cb callback, name string, attempt int, before *Prompt) (bxml.Response, error) {
+	menu := h.menus[name]
+	tag, err := formatTag(name, attempt, cb.tag)
+	if err != nil {
+		return bxml.Response{}, err
+	}
+
+	response := bxml.NewResponse()
+	if before != nil {
+		response = response.Append(h.prompt(*before))
+	}
+
+	gather := bxml.Gather{
+		MaxDigits:         menu.maxDigits(),
+		TerminatingDigits: menu.TerminatingDigits,
+		FirstDigitTimeout: menu.Timeout,
+		GatherUrl:         cb.url,
+		Tag:               tag,
+	}
+	return response.Gather(gather, func(g *bxml.GatherBuilder) {
+		for _, p := range menu.Prompts {
+			if p.Play != "" {
+				g.Play(p.Play)
+			} else {
+				g.SpeakSentence(h.say(p.Say))
+			}
+		}
+	}), nil
+}
+
+func (h *Handler) prompt(p Prompt) bxml.Verb {
+	if p.Play != "" {
+		return bxml.PlayAudio{AudioUri: p.Play}
+	}
+	return h.say(p.Say)
+}
+
+func (h *Handler) say(text string) bxml.SpeakSentence {
+	return bxml.SpeakSentence{Voice: h.flow.Voice, Sentence: text}
+}
+
+// formatTag returns the ivr tag of a menu, followed by the application's tag, if any.  Returns
+// bandwidth.ErrTagTooLong if the result exceeds bandwidth.MaxTagLength.
+func formatTag(menu string, attempt int, appTag string) (string, error) {
+	tag := tagPrefix + menu + ":" + strconv.Itoa(attempt)
+	if appTag != "" {
+		tag += ":" + appTag
+	}
+	if len(tag) > bandwidth.MaxTagLength {
+		return "", fmt.Errorf("unable to tag menu, %v, with application tag of %v characters: %w", menu, len(appTag), bandwidth.ErrTagTooLong)
+	}
+	return tag, nil
+}
+
+// parseTag returns the menu, attempt, and application tag of an ivr tag.  If the tag isn't one of
+// ours, or names an unknown menu, ok is false and the whole tag is the application's.
+func (h *Handler) parseTag(tag string) (menu string, attempt int, appTag string, ok bool) {
+	if !strings.HasPrefix(tag, tagPrefix) {
+		return "", 0, tag, false
+	}
+	segments := strings.SplitN(strings.TrimPrefix(tag, tagPrefix), ":", 3)
+	if len(segments) < 2 {
+		return "", 0, tag, false
+	}
+	attempt, err := strconv.Atoi(segments[1])
+	if err != nil {
+		return "", 0, tag, false
+	}
+	if _, ok := h.menus[segments[0]]; !ok {
+		return "", 0, tag, false
+	}
+	if len(segments) == 3 {
+		appTag = segments[2]
+	}
+	return segments[0], attempt, appTag, true
+}
@@ -0,0 +1,264 @@
+package ivr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/savaki/bandwidth"
+	"github.com/savaki/bandwidth/bxml"
+	"github.com/savaki/bandwidth/simulator"
+)
+
+func loadMenu(t *testing.T) Flow {
+	f, err := os.Open("testdata/menu.json")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer f.Close()
+
+	flow, err := Load(f)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return flow
+}
+
+func run(t *testing.T, flow Flow, digits ...string) *simulator.Result {
+	handler, err := Compile(flow)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	sim := simulator.New("/ivr", simulator.WithHandler(handler))
+	result, err := sim.Run(context.Background(), simulator.Script{Digits: digits})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return result
+}
+
+func TestTransfer(t *testing.T) {
+	result := run(t, loadMenu(t), "1")
+
+	want := []string{
+		"Press 1 for sales, 2 for billing, or 0 for an operator",
+		"Connecting you to sales",
+	}
+	if got := result.Transcript.Heard(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	last := result.Transcript[len(result.Transcript)-1]
+	if got, want := last.String(), "  4 Transfer: +18005550001"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestGotoAndBack(t *testing.T) {
+	result := run(t, loadMenu(t), "2", "9", "0")
+
+	var tags []string
+	for _, event := range result.Events {
+		if gather, ok := event.(*bandwidth.GatherEvent); ok {
+			tags = append(tags, gather.Tag)
+		}
+	}
+	if got, want := tags, []string{"ivr:main:0", "ivr:billing:0", "ivr:main:0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	last := result.Transcript[len(result.Transcript)-1]
+	if got, want := last.Text, "sip:operator@example.com"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	result := run(t, loadMenu(t), "7", "")
+
+	menu := "Press 1 for sales, 2 for billing, or 0 for an operator"
+	want := []string{
+		menu,
+		"Sorry, that is not a valid choice",
+		menu,
+		"Sorry, I didn't hear anything",
+		menu,
+		"Goodbye",
+	}
+	if got := result.Transcript.Heard(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Events[len(result.Events)-2].(*bandwidth.GatherEvent).Tag, "ivr:main:2"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRedirectResumesMenu(t *testing.T) {
+	handler, err := Compile(loadMenu(t))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	// an application handler may send the caller back into the ivr by tagging a redirect
+	event := bandwidth.RedirectEvent{EventType: "redirect", Tag: "ivr:billing:0"}
+	data, _ := json.Marshal(event)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ivr", bytes.NewReader(data)))
+
+	verbs, err := bxml.Parse(rec.Body)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	gather := verbs[0].(bxml.Gather)
+	if got, want := gather.Prompts[0].(bxml.SpeakSentence).Voice, "julie"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := gather.Tag, "ivr:billing:0"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestApplicationTag(t *testing.T) {
+	handler, err := Compile(loadMenu(t))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	sim := simulator.New("/ivr", simulator.WithHandler(handler))
+	result, err := sim.Run(context.Background(), simulator.Script{Tag: "order-42", Digits: []string{"2", "9", "1"}})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var tags []string
+	for _, event := range result.Events {
+		if gather, ok := event.(*bandwidth.GatherEvent); ok {
+			tags = append(tags, gather.Tag)
+		}
+	}
+	if got, want := tags, []string{"ivr:main:0:order-42", "ivr:billing:0:order-42", "ivr:main:0:order-42"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	// the application's tag is restored when the call leaves the ivr
+	if got, want := result.Tag, "order-42"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestGatherUrlKeepsPrefixAndQuery(t *testing.T) {
+	handler, err := Compile(loadMenu(t))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/ivr/", http.StripPrefix("/ivr", handler))
+
+	data, _ := json.Marshal(bandwidth.AnswerEvent{EventType: "answer"})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ivr/answer?bw_sig=abc", bytes.NewReader(data)))
+
+	verbs, err := bxml.Parse(rec.Body)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := verbs[0].(bxml.Gather).GatherUrl, "/ivr/answer?bw_sig=abc"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestApplicationTagTooLong(t *testing.T) {
+	handler, err := Compile(loadMenu(t))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	tag := strings.Repeat("x", bandwidth.MaxTagLength-len("ivr:main:0:"))
+	for _, tc := range []struct {
+		Tag  string
+		Want int
+	}{
+		{Tag: tag, Want: http.StatusOK},
+		{Tag: tag + "x", Want: http.StatusInternalServerError},
+	} {
+		data, _ := json.Marshal(bandwidth.AnswerEvent{EventType: "answer", Tag: tc.Tag})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ivr", bytes.NewReader(data)))
+		if got, want := rec.Code, tc.Want; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	handler, err := Compile(loadMenu(t))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	body := `{"eventType":"answer","tag":"` + strings.Repeat("x", maxBodySize) + `"}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ivr", strings.NewReader(body)))
+	if got, want := rec.Code, http.StatusBadRequest; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	flow := Flow{
+		Start: "main",
+		Menus: []Menu{
+			{
+				Name: "main",
+				Routes: []Route{
+					{Digits: "1", Action: Action{Goto: "missing"}},
+					{Digits: "1", Action: Action{Hangup: true}},
+					{Digits: "12", Action: Action{Hangup: true, Redirect: "/x"}},
+				},
+			},
+		},
+	}
+
+	_, err := Compile(flow)
+	if err == nil {
+		t.Fatalf("got nil; want error")
+	}
+	for _, want := range []string{
+		"menu, main, has no prompts",
+		`route "1" goes to unknown menu, "missing"`,
+		`route "1" is declared more than once`,
+		`route "12" is longer than maxDigits, 1`,
+		`route "12" must have exactly one of goto, transfer, redirect, or hangup`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("got %v; want %v", err, want)
+		}
+	}
+}
+
+func TestLoadFunc(t *testing.T) {
+	data := []byte(`{"start":"main","menus":[{"name":"main","prompts":[{"play":"https://example.com/menu.wav"}],"routes":[{"digits":"1","hangup":true}]}]}`)
+
+	var called bool
+	unmarshal := func(data []byte, v interface{}) error {
+		called = true
+		return json.Unmarshal(data, v)
+	}
+
+	flow, err := LoadFunc(data, unmarshal)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !called {
+		t.Fatalf("got false; want unmarshal called")
+	}
+	if got, want := flow.Menus[0].Routes[0].Hangup, true; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,341 @@
+// Package journal records callbacks verbatim to a local log so they may be audited and replayed.
+// The log is a directory of JSON lines segments; a new segment is started when the current one
+// reaches its size limit, and the oldest segments are removed beyond the retention limit.
+//
+//	j, err := journal.Open("/var/log/callbacks")
+//	http.Handle("/callbacks", j.Middleware(router))
+//
+//	// later, reproduce an incident locally
+//	results, err := journal.Replay(ctx, "callbacks", from, to, router)
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/savaki/bandwidth"
+)
+
+const (
+	segmentPrefix = "journal-"
+	segmentSuffix = ".jsonl"
+	maxBodySize   = 1 << 20
+)
+
+// Entry is a single callback and the response sent
+type Entry struct {
+	Time      time.Time   `json:"time"`
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      string      `json:"body"`
+	EventType string      `json:"eventType,omitempty"`
+	CallId    string      `json:"callId,omitempty"`
+	Status    int         `json:"status"`
+	Response  string      `json:"response,omitempty"` // Response - BXML returned, if any
+}
+
+type options struct {
+	maxSegmentSize int64
+	maxSegments    int
+	redact         []string
+}
+
+// Option configures a Journal
+type Option func(*options)
+
+// WithMaxSegmentSize starts a new segment once the current segment reaches n bytes; defaults to 64MB
+func WithMaxSegmentSize(n int64) Option {
+	return func(o *options) {
+		o.maxSegmentSize = n
+	}
+}
+
+// WithMaxSegments removes the oldest segments once there are more than n; defaults to 0, keep all
+func WithMaxSegments(n int) Option {
+	return func(o *options) {
+		o.maxSegments = n
+	}
+}
+
+// WithRedactedHeaders omits the given headers from entries.  Authorization is always omitted; see
+// WithReplayBasicAuth.
+func WithRedactedHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.redact = append(o.redact, headers...)
+	}
+}
+
+// Journal appends entries to the segments in a directory.  Journal is safe for concurrent use.
+type Journal struct {
+	dir     string
+	options options
+	now     func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	segment int
+}
+
+// Open opens, or creates, the journal in dir and appends to its newest segment
+func Open(dir string, opts ...Option) (*Journal, error) {
+	options := options{
+		maxSegmentSize: 64 << 20,
+		redact:         []string{"Authorization"},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create journal, %v: %w", dir, err)
+	}
+
+	segments, err := segments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{
+		dir:     dir,
+		options: options,
+		now:     time.Now,
+	}
+	segment := 1
+	if len(segments) > 0 {
+		segment = segments[len(segments)-1]
+	}
+	if err := j.open(segment); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Append writes entry to the current segment, starting a new segment if it is full
+func (j *Journal) Append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to marshal journal entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return fmt.Errorf("unable to append to journal, %v: journal closed", j.dir)
+	}
+	if j.size > 0 && j.size+int64(len(data)) > j.options.maxSegmentSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.file.Write(data)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("unable to append to journal, %v: %w", j.file.Name(), err)
+	}
+	return nil
+}
+
+// Close closes the current segment
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Middleware returns a handler that records each callback, and the response written by next.
+// Failures to write the journal do not affect the response.
+func (j *Journal) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received := j.now().UTC()
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to read callback: %v", err), http.StatusBadRequest)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		entry := Entry{
+			Time:     received,
+			Method:   req.Method,
+			Path:     req.URL.RequestURI(),
+			Header:   j.header(req.Header),
+			Body:     string(body),
+			Status:   rec.status,
+			Response: rec.body.String(),
+		}
+		if event, err := bandwidth.ParseEvent(body); err == nil {
+			entry.EventType = event.GetEventType()
+			entry.CallId = event.GetCallId()
+		}
+		j.Append(entry)
+	})
+}
+
+func (j *Journal) header(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range j.options.redact {
+		h.Del(name)
+	}
+	return h
+}
+
+// rotate closes the current segment, starts the next, and removes segments beyond the limit
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("unable to close journal segment, %v: %w", j.file.Name(), err)
+	}
+	j.file = nil
+	if err := j.open(j.segment + 1); err != nil {
+		return err
+	}
+
+	if j.options.maxSegments <= 0 {
+		return nil
+	}
+	segments, err := segments(j.dir)
+	if err != nil {
+		return err
+	}
+	for len(segments) > j.options.maxSegments {
+		filename := segmentName(j.dir, segments[0])
+		if err := os.Remove(filename); err != nil {
+			return fmt.Errorf("unable to remove journal segment, %v: %w", filename, err)
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
+
+func (j *Journal) open(segment int) error {
+	filename := segmentName(j.dir, segment)
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open journal segment, %v: %w", filename, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("unable to stat journal segment, %v: %w", filename, err)
+	}
+
+	j.file = f
+	j.size = info.Size()
+	j.segment = segment
+	return nil
+}
+
+// Read calls fn, in order, with each entry in dir received from from, inclusive, until to,
+// exclusive.  A zero from or to leaves that end of the range open.
+func Read(dir string, from, to time.Time, fn func(Entry) error) error {
+	segments, err := segments(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if err := readSegment(segmentName(dir, segment), from, to, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readSegment(filename string, from, to time.Time, fn func(Entry) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("unable to open journal segment, %v: %w", filename, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 4*maxBodySize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a partially written final line is ignored
+			continue
+		}
+		if !from.IsZero() && entry.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !entry.Time.Before(to) {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read journal segment, %v: %w", filename, err)
+	}
+	return nil
+}
+
+// segments returns the sequence numbers of the segments in dir, in order
+func segments(dir string) ([]int, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read journal, %v: %w", dir, err)
+	}
+
+	var segments []int
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		var segment int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(name, segmentPrefix), "%d", &segment); err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	sort.Ints(segments)
+	return segments, nil
+}
+
+func segmentName(dir string, segment int) string {
+	return filepath.Join(dir, fmt.Sprintf("%v%06d%v", segmentPrefix, segment, segmentSuffix))
+}
+
+// recorder captures the status and body written by a handler
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
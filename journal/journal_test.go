@@ -0,0 +1,189 @@
+package journal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/savaki/bandwidth"
+	"github.com/savaki/bandwidth/bxml"
+	"github.com/savaki/bandwidth/fixture"
+)
+
+func TestMiddlewareAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	j.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	greeting := "hello"
+	router := bandwidth.NewCallbackRouter()
+	router.OnAnswer(func(ctx context.Context, event *bandwidth.AnswerEvent) (bxml.Response, error) {
+		return bxml.NewResponse().Speak(greeting), nil
+	})
+	h := j.Middleware(router)
+
+	g := fixture.New()
+	for _, eventType := range []string{"answer", "disconnect"} {
+		data, err := g.JSON(eventType)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/callbacks?leg=a", strings.NewReader(string(data)))
+		req.SetBasicAuth("user", "secret")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	j.Close()
+
+	var entries []Entry
+	if err := Read(dir, time.Time{}, time.Time{}, func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	answer := entries[0]
+	if got, want := answer.EventType, "answer"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := answer.CallId, g.CallId(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := answer.Path, "/callbacks?leg=a"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := answer.Header.Get("Authorization"); got != "" {
+		t.Fatalf("got %v; want redacted", got)
+	}
+	if !strings.Contains(answer.Response, "hello") {
+		t.Fatalf("got %v; want hello", answer.Response)
+	}
+
+	// replay only the answer, after changing the handler's behaviour
+	greeting = "goodbye"
+	results, err := Replay(context.Background(), dir, entries[0].Time, entries[1].Time, router)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(results), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if !results[0].Changed() || !strings.Contains(results[0].Response, "goodbye") {
+		t.Fatalf("got %v; want changed response", results[0].Response)
+	}
+
+	var eventTypes []string
+	err = ReplayEvents(context.Background(), dir, time.Time{}, time.Time{}, func(ctx context.Context, event bandwidth.Event) error {
+		eventTypes = append(eventTypes, event.GetEventType())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := strings.Join(eventTypes, ","), "answer,disconnect"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestReplayAuthenticated(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	greeting := "hello"
+	router := bandwidth.NewCallbackRouter()
+	router.OnAnswer(func(ctx context.Context, event *bandwidth.AnswerEvent) (bxml.Response, error) {
+		return bxml.NewResponse().Speak(greeting), nil
+	})
+	auth, err := bandwidth.NewCallbackAuth(bandwidth.WithCallbackCredentials("user", "secret"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	h := auth.Wrap(bandwidth.GuardCallback(router))
+
+	data, err := fixture.New().JSON("answer")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader(string(data)))
+	req.SetBasicAuth("user", "secret")
+	j.Middleware(h).ServeHTTP(httptest.NewRecorder(), req)
+	j.Close()
+
+	// the recorded callback has no credentials
+	results, err := Replay(context.Background(), dir, time.Time{}, time.Time{}, h)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := results[0].Status, http.StatusUnauthorized; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	greeting = "goodbye"
+	results, err = Replay(context.Background(), dir, time.Time{}, time.Time{}, h, WithReplayBasicAuth("user", "secret"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := results[0].Status, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if !results[0].Changed() || !strings.Contains(results[0].Response, "goodbye") {
+		t.Fatalf("got %v; want changed response", results[0].Response)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir, WithMaxSegmentSize(200), WithMaxSegments(2))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	base := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		entry := Entry{Time: base.Add(time.Duration(i) * time.Second), Body: strings.Repeat("x", 100), Status: http.StatusOK}
+		if err := j.Append(entry); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+	j.Close()
+
+	files, _ := os.ReadDir(dir)
+	if got, want := len(files), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var times []time.Time
+	Read(dir, time.Time{}, time.Time{}, func(entry Entry) error {
+		times = append(times, entry.Time)
+		return nil
+	})
+	if got, want := times[len(times)-1], base.Add(4*time.Second); !got.Equal(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// reopening appends to the newest segment
+	j, err = Open(dir, WithMaxSegmentSize(1<<20))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer j.Close()
+	if got, want := j.segment, 5; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
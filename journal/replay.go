@@ -0,0 +1,109 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/savaki/bandwidth"
+)
+
+// Result is the outcome of replaying an entry
+type Result struct {
+	Entry    Entry
+	Status   int
+	Response string
+}
+
+// Changed returns true if the replayed response differs from the response recorded
+func (r Result) Changed() bool {
+	return r.Status != r.Entry.Status || r.Response != r.Entry.Response
+}
+
+type replayOptions struct {
+	header http.Header
+}
+
+// ReplayOption configures Replay
+type ReplayOption func(*replayOptions)
+
+// WithReplayHeader sets a header on every request replayed, replacing the recorded value; e.g. to
+// supply a header redacted from the journal
+func WithReplayHeader(name, value string) ReplayOption {
+	return func(o *replayOptions) {
+		o.header.Set(name, value)
+	}
+}
+
+// WithReplayBasicAuth sets the credentials of every request replayed.  Authorization is never
+// recorded, so replaying through a handler wrapped by bandwidth.CallbackAuth requires this.
+func WithReplayBasicAuth(username, password string) ReplayOption {
+	return func(o *replayOptions) {
+		req := http.Request{Header: o.header}
+		req.SetBasicAuth(username, password)
+	}
+}
+
+// Replay sends each entry in dir received in [from, to) to h, typically a
+// bandwidth.CallbackRouter, in the order received, and returns the responses.
+//
+// The Authorization header is not recorded; use WithReplayBasicAuth when h requires credentials.
+// A router configured with bandwidth.WithSeenStore returns the responses it stored for callbacks
+// it has already seen rather than calling its handlers again, so replay through a router with a
+// new, empty store for Result.Changed to be meaningful.
+func Replay(ctx context.Context, dir string, from, to time.Time, h http.Handler, opts ...ReplayOption) ([]Result, error) {
+	options := replayOptions{
+		header: http.Header{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var results []Result
+	err := Read(dir, from, to, func(entry Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, entry.Method, entry.Path, strings.NewReader(entry.Body))
+		if err != nil {
+			return fmt.Errorf("unable to replay entry received at %v: %w", entry.Time, err)
+		}
+		for name, values := range entry.Header {
+			req.Header[name] = values
+		}
+		for name, values := range options.header {
+			req.Header[name] = values
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		results = append(results, Result{
+			Entry:    entry,
+			Status:   w.Code,
+			Response: w.Body.String(),
+		})
+		return nil
+	})
+	return results, err
+}
+
+// ReplayEvents parses each entry in dir received in [from, to) and passes the event to fn, in
+// the order received, e.g. to rebuild a bandwidth.Roster or drive a ConferenceController.
+// Entries that are not callbacks are skipped.
+func ReplayEvents(ctx context.Context, dir string, from, to time.Time, fn func(ctx context.Context, event bandwidth.Event) error) error {
+	return Read(dir, from, to, func(entry Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		event, err := bandwidth.ParseEvent([]byte(entry.Body))
+		if err != nil {
+			return nil
+		}
+		return fn(ctx, event)
+	})
+}
@@ -0,0 +1,385 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RosterChangeType identifies what changed in a Roster
+type RosterChangeType string
+
+const (
+	RosterConferenceCreated   RosterChangeType = "conferenceCreated"   // RosterConferenceCreated - a conference was created
+	RosterConferenceCompleted RosterChangeType = "conferenceCompleted" // RosterConferenceCompleted - a conference ended
+	RosterMemberJoined        RosterChangeType = "memberJoined"        // RosterMemberJoined - a call joined a conference
+	RosterMemberExited        RosterChangeType = "memberExited"        // RosterMemberExited - a call left a conference
+	RosterMemberUpdated       RosterChangeType = "memberUpdated"       // RosterMemberUpdated - mute, hold, or coaching state changed
+)
+
+// RosterMember is a single call in a conference
+type RosterMember struct {
+	CallId         string
+	From           string
+	To             string
+	JoinTime       time.Time
+	ExitTime       time.Time // ExitTime - zero while the member is still in the conference
+	Mute           bool
+	Hold           bool
+	CallIdsToCoach []string
+}
+
+// Present returns true if the member has not left the conference
+func (m RosterMember) Present() bool {
+	return m.ExitTime.IsZero()
+}
+
+// RosterConference is a snapshot of a conference and its members, past and present
+type RosterConference struct {
+	ConferenceId  string
+	Name          string
+	Tag           string
+	CreatedTime   time.Time
+	CompletedTime time.Time      // CompletedTime - zero while the conference is active
+	Members       []RosterMember // Members - ordered by the time they first joined
+}
+
+// Present returns the members currently in the conference
+func (c RosterConference) Present() []RosterMember {
+	var members []RosterMember
+	for _, m := range c.Members {
+		if m.Present() {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+// Coaches returns the call ids currently coaching the given call
+func (c RosterConference) Coaches(callId string) []string {
+	var coaches []string
+	for _, m := range c.Members {
+		if !m.Present() {
+			continue
+		}
+		for _, id := range m.CallIdsToCoach {
+			if id == callId {
+				coaches = append(coaches, m.CallId)
+			}
+		}
+	}
+	return coaches
+}
+
+// RosterChange describes a single change to the roster
+type RosterChange struct {
+	Type       RosterChangeType
+	Conference RosterConference // Conference - snapshot of the conference after the change
+	Member     *RosterMember    // Member - the member that changed; nil for conference level changes
+}
+
+type rosterConference struct {
+	RosterConference
+	index map[string]int // index of call id to position in Members
+}
+
+// Roster maintains the live membership of conferences from conference callbacks.  Roster is safe
+// for concurrent use.
+type Roster struct {
+	voice *Voice
+	now   func() time.Time
+
+	mu          sync.Mutex
+	conferences map[string]*rosterConference
+	nextId      int
+	subscribers map[int]func(RosterChange)
+}
+
+// NewRoster returns an empty roster; voice is used by Reconcile and may be nil if reconciliation is
+// not required
+func NewRoster(voice *Voice) *Roster {
+	return &Roster{
+		voice:       voice,
+		now:         time.Now,
+		conferences: map[string]*rosterConference{},
+		subscribers: map[int]func(RosterChange){},
+	}
+}
+
+// Subscribe registers fn to be called, in order, with every roster change.  Call the returned func
+// to unsubscribe.
+func (r *Roster) Subscribe(fn func(RosterChange)) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextId
+	r.nextId++
+	r.subscribers[id] = fn
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.subscribers, id)
+	}
+}
+
+// Conference returns a snapshot of the conference with the given id
+func (r *Roster) Conference(conferenceId string) (RosterConference, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.conferences[conferenceId]
+	if !ok {
+		return RosterConference{}, false
+	}
+	return c.snapshot(), true
+}
+
+// Conferences returns snapshots of every conference in the roster
+func (r *Roster) Conferences() []RosterConference {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conferences := make([]RosterConference, 0, len(r.conferences))
+	for _, c := range r.conferences {
+		conferences = append(conferences, c.snapshot())
+	}
+	return conferences
+}
+
+// Remove discards a conference from the roster, typically once it has completed
+func (r *Roster) Remove(conferenceId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conferences, conferenceId)
+}
+
+// Apply updates the roster from a conference callback.  Events that do not relate to conference
+// membership are ignored.
+func (r *Roster) Apply(event Event) error {
+	var changes []RosterChange
+	now := r.timeOf(event)
+
+	r.mu.Lock()
+	switch v := event.(type) {
+	case *ConferenceCreatedEvent:
+		c := r.conference(v.ConferenceId, v.Name, v.Tag)
+		c.CreatedTime = now
+		changes = append(changes, RosterChange{Type: RosterConferenceCreated, Conference: c.snapshot()})
+
+	case *ConferenceMemberJoinEvent:
+		c := r.conference(v.ConferenceId, v.Name, v.Tag)
+		member := c.member(v.CallId)
+		*member = RosterMember{
+			CallId:   v.CallId,
+			From:     v.From,
+			To:       v.To,
+			JoinTime: now,
+		}
+		changes = append(changes, c.change(RosterMemberJoined, v.CallId))
+
+	case *ConferenceMemberExitEvent:
+		c := r.conference(v.ConferenceId, v.Name, v.Tag)
+		member := c.member(v.CallId)
+		member.CallId = v.CallId
+		if member.From == "" {
+			member.From = v.From
+		}
+		if member.To == "" {
+			member.To = v.To
+		}
+		member.ExitTime = now
+		changes = append(changes, c.change(RosterMemberExited, v.CallId))
+
+	case *ConferenceCompletedEvent:
+		c := r.conference(v.ConferenceId, v.Name, v.Tag)
+		c.CompletedTime = now
+		for i := range c.Members {
+			if c.Members[i].Present() {
+				c.Members[i].ExitTime = now
+			}
+		}
+		changes = append(changes, RosterChange{Type: RosterConferenceCompleted, Conference: c.snapshot()})
+	}
+	subscribers := r.subscriberList()
+	r.mu.Unlock()
+
+	notify(subscribers, changes)
+	return nil
+}
+
+// timeOf returns the time an event was generated or, if the event has no time, the current time
+func (r *Roster) timeOf(event Event) time.Time {
+	if t := event.GetEventTime(); !t.IsZero() {
+		return t
+	}
+	return r.now()
+}
+
+// Reconcile refreshes the conference and its present members from the api, notifying subscribers
+// of any member whose mute, hold, or coaching state differs from the roster
+func (r *Roster) Reconcile(ctx context.Context, conferenceId string) error {
+	if r.voice == nil {
+		return fmt.Errorf("unable to reconcile conference, %v: roster has no voice client", conferenceId)
+	}
+
+	conference, err := r.voice.FindConference(ctx, conferenceId)
+	if err != nil {
+		return fmt.Errorf("unable to reconcile conference, %v: %w", conferenceId, err)
+	}
+
+	r.mu.Lock()
+	c := r.conference(conferenceId, conference.Name, conference.Tag)
+	if t, err := time.Parse(time.RFC3339, conference.CreatedTime); err == nil {
+		c.CreatedTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, conference.CompletedTime); err == nil {
+		c.CompletedTime = t
+	}
+	var callIds []string
+	for _, m := range c.Members {
+		if m.Present() {
+			callIds = append(callIds, m.CallId)
+		}
+	}
+	r.mu.Unlock()
+
+	var (
+		changes []RosterChange
+		errs    []string
+	)
+	for _, callId := range callIds {
+		got, err := r.voice.FindConferenceMember(ctx, conferenceId, callId)
+		if e, ok := asError(err); ok && e.StatusCode == http.StatusNotFound {
+			// the exit callback was missed; the member is no longer in the conference
+			r.mu.Lock()
+			member := c.member(callId)
+			if member.Present() {
+				member.ExitTime = r.now()
+				changes = append(changes, c.change(RosterMemberExited, callId))
+			}
+			r.mu.Unlock()
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("member, %v: %v", callId, err))
+			continue
+		}
+
+		r.mu.Lock()
+		member := c.member(callId)
+		mute, _ := strconv.ParseBool(got.Mute)
+		hold, _ := strconv.ParseBool(got.Hold)
+		coach := splitCallIds(got.CallIdsToCoach)
+		if member.Mute != mute || member.Hold != hold || strings.Join(member.CallIdsToCoach, ",") != strings.Join(coach, ",") {
+			member.Mute = mute
+			member.Hold = hold
+			member.CallIdsToCoach = coach
+			changes = append(changes, c.change(RosterMemberUpdated, callId))
+		}
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	subscribers := r.subscriberList()
+	r.mu.Unlock()
+
+	notify(subscribers, changes)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to reconcile conference, %v: %v", conferenceId, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// asError returns the api error wrapped by err, if any
+func asError(err error) (Error, bool) {
+	var e Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return Error{}, false
+}
+
+// conference must be called while holding the lock
+func (r *Roster) conference(conferenceId, name, tag string) *rosterConference {
+	c, ok := r.conferences[conferenceId]
+	if !ok {
+		c = &rosterConference{
+			RosterConference: RosterConference{ConferenceId: conferenceId},
+			index:            map[string]int{},
+		}
+		r.conferences[conferenceId] = c
+	}
+	if name != "" {
+		c.Name = name
+	}
+	if tag != "" {
+		c.Tag = tag
+	}
+	return c
+}
+
+// subscriberList must be called while holding the lock
+func (r *Roster) subscriberList() []func(RosterChange) {
+	subscribers := make([]func(RosterChange), 0, len(r.subscribers))
+	for id := 0; id < r.nextId; id++ {
+		if fn, ok := r.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	return subscribers
+}
+
+func (c *rosterConference) member(callId string) *RosterMember {
+	i, ok := c.index[callId]
+	if !ok {
+		i = len(c.Members)
+		c.index[callId] = i
+		c.Members = append(c.Members, RosterMember{CallId: callId})
+	}
+	return &c.Members[i]
+}
+
+func (c *rosterConference) change(changeType RosterChangeType, callId string) RosterChange {
+	snapshot := c.snapshot()
+	member := snapshot.Members[c.index[callId]]
+	return RosterChange{
+		Type:       changeType,
+		Conference: snapshot,
+		Member:     &member,
+	}
+}
+
+func (c *rosterConference) snapshot() RosterConference {
+	snapshot := c.RosterConference
+	snapshot.Members = make([]RosterMember, len(c.Members))
+	for i, m := range c.Members {
+		m.CallIdsToCoach = append([]string(nil), m.CallIdsToCoach...)
+		snapshot.Members[i] = m
+	}
+	return snapshot
+}
+
+func notify(subscribers []func(RosterChange), changes []RosterChange) {
+	for _, change := range changes {
+		for _, fn := range subscribers {
+			fn(change)
+		}
+	}
+}
+
+func splitCallIds(s string) []string {
+	var callIds []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			callIds = append(callIds, id)
+		}
+	}
+	return callIds
+}
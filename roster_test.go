@@ -0,0 +1,160 @@
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/account/conferences/conf-1":
+			io.WriteString(w, `{"id":"conf-1","name":"support","createdTime":"2020-08-27T00:01:26Z"}`)
+		case "/account/conferences/conf-1/members/c-agent":
+			io.WriteString(w, `{"callId":"c-agent","mute":"false","hold":"false"}`)
+		case "/account/conferences/conf-1/members/c-coach":
+			io.WriteString(w, `{"callId":"c-coach","mute":"true","callIdsToCoach":"c-agent"}`)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	var (
+		ctx     = context.Background()
+		voice   = &Voice{client: newClient(server.URL, "account", "username", "password")}
+		roster  = NewRoster(voice)
+		now     = time.Date(2020, 8, 27, 0, 0, 0, 0, time.UTC)
+		changes []RosterChange
+	)
+	roster.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	unsubscribe := roster.Subscribe(func(change RosterChange) {
+		changes = append(changes, change)
+	})
+
+	events := []Event{
+		&ConferenceCreatedEvent{ConferenceId: "conf-1", Name: "support"},
+		&ConferenceMemberJoinEvent{ConferenceId: "conf-1", Name: "support", CallId: "c-caller", From: "+15551112222"},
+		&ConferenceMemberJoinEvent{ConferenceId: "conf-1", Name: "support", CallId: "c-agent"},
+		&ConferenceMemberJoinEvent{ConferenceId: "conf-1", Name: "support", CallId: "c-coach"},
+		&ConferenceMemberExitEvent{ConferenceId: "conf-1", Name: "support", CallId: "c-caller"},
+	}
+	for _, event := range events {
+		if err := roster.Apply(event); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	conference, ok := roster.Conference("conf-1")
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if got, want := len(conference.Members), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(conference.Present()), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if caller := conference.Members[0]; caller.Present() || !caller.ExitTime.After(caller.JoinTime) {
+		t.Fatalf("got join %v, exit %v; want caller to have exited after joining", caller.JoinTime, caller.ExitTime)
+	}
+
+	if err := roster.Reconcile(ctx, "conf-1"); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	conference, _ = roster.Conference("conf-1")
+	if got, want := conference.Coaches("c-agent"), []string{"c-coach"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if !conference.Members[2].Mute {
+		t.Fatalf("got false; want coach muted")
+	}
+
+	unsubscribe()
+	if err := roster.Apply(&ConferenceCompletedEvent{ConferenceId: "conf-1", Name: "support"}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	wantTypes := []RosterChangeType{
+		RosterConferenceCreated,
+		RosterMemberJoined,
+		RosterMemberJoined,
+		RosterMemberJoined,
+		RosterMemberExited,
+		RosterMemberUpdated, // c-coach muted and coaching; c-agent unchanged
+	}
+	if got, want := len(changes), len(wantTypes); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i, want := range wantTypes {
+		if got := changes[i].Type; got != want {
+			t.Fatalf("change %v: got %v; want %v", i, got, want)
+		}
+	}
+
+	conference, _ = roster.Conference("conf-1")
+	if conference.CompletedTime.IsZero() || len(conference.Present()) != 0 {
+		t.Fatalf("got %v present members; want conference completed with none present", len(conference.Present()))
+	}
+}
+
+func TestRosterReconcileMissedExit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/account/conferences/conf-1":
+			io.WriteString(w, `{"id":"conf-1","name":"support"}`)
+		case "/account/conferences/conf-1/members/c-error":
+			http.Error(w, `{"type":"internal","description":"boom"}`, http.StatusInternalServerError)
+		case "/account/conferences/conf-1/members/c-agent":
+			io.WriteString(w, `{"callId":"c-agent","mute":"true"}`)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	voice := &Voice{client: newClient(server.URL, "account", "username", "password")}
+	roster := NewRoster(voice)
+	for _, callId := range []string{"c-gone", "c-error", "c-agent"} {
+		if err := roster.Apply(&ConferenceMemberJoinEvent{ConferenceId: "conf-1", Name: "support", CallId: callId}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	var changes []RosterChange
+	roster.Subscribe(func(change RosterChange) {
+		changes = append(changes, change)
+	})
+
+	err := roster.Reconcile(context.Background(), "conf-1")
+	if err == nil || !strings.Contains(err.Error(), "c-error") {
+		t.Fatalf("got %v; want error for c-error", err)
+	}
+
+	// the missing member is marked exited and the remaining members are still reconciled
+	if got, want := len(changes), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := changes[0].Type, RosterMemberExited; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := changes[0].Member.CallId, "c-gone"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := changes[1].Type, RosterMemberUpdated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	conference, _ := roster.Conference("conf-1")
+	if got, want := len(conference.Present()), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,415 @@
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+// maxCallbackSize limits the size of a callback body read by CallbackRouter
+const maxCallbackSize = 1 << 20
+
+// CallbackFunc handles a single callback and returns the BXML to execute.  An empty Response
+// writes an empty 200.
+type CallbackFunc func(ctx context.Context, event Event) (bxml.Response, error)
+
+type routerOptions struct {
+	fallback *bxml.Response
+	onError  func(ctx context.Context, event Event, err error)
+	seen     SeenStore
+	bus      *EventBus
+	publish  time.Duration
+}
+
+// RouterOption configures a CallbackRouter
+type RouterOption func(*routerOptions)
+
+// WithFallbackResponse serves response, with a 200, when a handler returns an error.  Without a
+// fallback the router responds 500 so Bandwidth retries the callback's fallback url.
+func WithFallbackResponse(response bxml.Response) RouterOption {
+	return func(o *routerOptions) {
+		o.fallback = &response
+	}
+}
+
+// WithErrorHandler calls fn with every error returned by a handler, e.g. to log it
+func WithErrorHandler(fn func(ctx context.Context, event Event, err error)) RouterOption {
+	return func(o *routerOptions) {
+		o.onError = fn
+	}
+}
+
+// WithEventBus publishes every callback to bus, including those without a handler, once it has
+// been handled successfully; callbacks whose handler fails are published when a retry succeeds.
+// Duplicates detected by WithSeenStore are not published again.  Publishing happens before the
+// response is written, so subscribers with the Block policy may delay it by up to the publish
+// timeout; subscribers with the default DropOldest policy never do.
+func WithEventBus(bus *EventBus) RouterOption {
+	return func(o *routerOptions) {
+		o.bus = bus
+	}
+}
+
+// WithPublishTimeout limits how long the router waits on subscribers with the Block policy before
+// responding; defaults to 1s.  Events are published independently of the callback's context, so a
+// slow subscriber never cancels the handler.
+func WithPublishTimeout(d time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.publish = d
+	}
+}
+
+// CallbackRouter is an http.Handler that parses callbacks and dispatches each to the handler
+// registered for its event type, writing the BXML returned.  Callbacks without a handler,
+// including event types this package doesn't know, receive an empty 200.
+//
+//	router := bandwidth.NewCallbackRouter()
+//	router.OnAnswer(func(ctx context.Context, event *bandwidth.AnswerEvent) (bxml.Response, error) {
+//		return bxml.NewResponse().Speak("Hello").Hangup(), nil
+//	})
+//	http.Handle("/callbacks", router)
+//
+// Handlers should be registered before the router starts serving requests.
+type CallbackRouter struct {
+	options  routerOptions
+	handlers map[string]CallbackFunc
+}
+
+// NewCallbackRouter returns an empty CallbackRouter
+func NewCallbackRouter(opts ...RouterOption) *CallbackRouter {
+	options := routerOptions{
+		publish: time.Second,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &CallbackRouter{
+		options:  options,
+		handlers: map[string]CallbackFunc{},
+	}
+}
+
+// Handle registers fn for callbacks with the given event type e.g. answer, replacing any handler
+// previously registered
+func (r *CallbackRouter) Handle(eventType string, fn CallbackFunc) {
+	r.handlers[eventType] = fn
+}
+
+// ServeHTTP parses the callback and writes the BXML returned by its handler
+func (r *CallbackRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxCallbackSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read callback: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	eventType, err := readEventType(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse callback: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fn, ok := r.handlers[eventType]
+	if !ok && r.options.bus == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event, err := parseEvent(eventType, data, false)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse callback: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	ctx := req.Context()
+	var (
+		key      string
+		reserved bool
+	)
+	if r.options.seen != nil {
+		key = EventKey(event)
+		data, ok, err := r.options.seen.Reserve(ctx, key)
+		if err != nil {
+			// an unavailable store should not prevent calls from being handled
+			r.handleError(ctx, event, fmt.Errorf("unable to check seen store: %w", err))
+		} else if ok {
+			writeBXML(w, data)
+			return
+		} else {
+			reserved = true
+		}
+	}
+	defer func() {
+		// release the reservation if the callback failed, or panicked, so a retry is processed
+		if reserved {
+			if err := r.options.seen.Release(context.Background(), key); err != nil {
+				r.handleError(ctx, event, fmt.Errorf("unable to release seen store key: %w", err))
+			}
+		}
+	}()
+
+	if fn == nil {
+		if reserved {
+			reserved = false
+			if err := r.options.seen.Store(ctx, key, nil); err != nil {
+				r.handleError(ctx, event, fmt.Errorf("unable to update seen store: %w", err))
+			}
+		}
+		r.publish(ctx, event)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	response, err := fn(ctx, event)
+	if err != nil {
+		r.handleError(ctx, event, err)
+		if r.options.fallback == nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		writeResponse(w, *r.options.fallback)
+		return
+	}
+
+	var body []byte
+	if response.Len() > 0 {
+		if body, err = response.Bytes(); err != nil {
+			r.handleError(ctx, event, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	if reserved {
+		reserved = false
+		if err := r.options.seen.Store(ctx, key, body); err != nil {
+			r.handleError(ctx, event, fmt.Errorf("unable to update seen store: %w", err))
+		}
+	}
+	r.publish(ctx, event)
+	writeBXML(w, body)
+}
+
+// publish sends event to the bus, if any, waiting no longer than the publish timeout
+func (r *CallbackRouter) publish(ctx context.Context, event Event) {
+	if r.options.bus == nil {
+		return
+	}
+
+	publishCtx, cancel := context.WithTimeout(context.Background(), r.options.publish)
+	defer cancel()
+
+	if err := r.options.bus.Publish(publishCtx, event); err != nil {
+		r.handleError(ctx, event, fmt.Errorf("unable to publish event: %w", err))
+	}
+}
+
+func (r *CallbackRouter) handleError(ctx context.Context, event Event, err error) {
+	if r.options.onError != nil {
+		r.options.onError(ctx, event, err)
+	}
+}
+
+// writeResponse writes response or, if empty, an empty 200
+func writeResponse(w http.ResponseWriter, response bxml.Response) {
+	if response.Len() == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	body, err := response.Bytes()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeBXML(w, body)
+}
+
+// writeBXML writes an encoded BXML document or, if empty, an empty 200
+func writeBXML(w http.ResponseWriter, body []byte) {
+	if len(body) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", bxml.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// OnAnswer registers fn for answer callbacks
+func (r *CallbackRouter) OnAnswer(fn func(ctx context.Context, event *AnswerEvent) (bxml.Response, error)) {
+	r.Handle("answer", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*AnswerEvent))
+	})
+}
+
+// OnBridgeComplete registers fn for bridgeComplete callbacks
+func (r *CallbackRouter) OnBridgeComplete(fn func(ctx context.Context, event *BridgeCompleteEvent) (bxml.Response, error)) {
+	r.Handle("bridgeComplete", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*BridgeCompleteEvent))
+	})
+}
+
+// OnBridgeTargetComplete registers fn for bridgeTargetComplete callbacks
+func (r *CallbackRouter) OnBridgeTargetComplete(fn func(ctx context.Context, event *BridgeTargetCompleteEvent) (bxml.Response, error)) {
+	r.Handle("bridgeTargetComplete", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*BridgeTargetCompleteEvent))
+	})
+}
+
+// OnConferenceCreated registers fn for conferenceCreated callbacks
+func (r *CallbackRouter) OnConferenceCreated(fn func(ctx context.Context, event *ConferenceCreatedEvent) (bxml.Response, error)) {
+	r.Handle("conferenceCreated", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*ConferenceCreatedEvent))
+	})
+}
+
+// OnConferenceMemberJoin registers fn for conferenceMemberJoin callbacks
+func (r *CallbackRouter) OnConferenceMemberJoin(fn func(ctx context.Context, event *ConferenceMemberJoinEvent) (bxml.Response, error)) {
+	r.Handle("conferenceMemberJoin", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*ConferenceMemberJoinEvent))
+	})
+}
+
+// OnConferenceMemberExit registers fn for conferenceMemberExit callbacks
+func (r *CallbackRouter) OnConferenceMemberExit(fn func(ctx context.Context, event *ConferenceMemberExitEvent) (bxml.Response, error)) {
+	r.Handle("conferenceMemberExit", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*ConferenceMemberExitEvent))
+	})
+}
+
+// OnConferenceCompleted registers fn for conferenceCompleted callbacks
+func (r *CallbackRouter) OnConferenceCompleted(fn func(ctx context.Context, event *ConferenceCompletedEvent) (bxml.Response, error)) {
+	r.Handle("conferenceCompleted", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*ConferenceCompletedEvent))
+	})
+}
+
+// OnConferenceRedirect registers fn for conferenceRedirect callbacks
+func (r *CallbackRouter) OnConferenceRedirect(fn func(ctx context.Context, event *ConferenceRedirectEvent) (bxml.Response, error)) {
+	r.Handle("conferenceRedirect", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*ConferenceRedirectEvent))
+	})
+}
+
+// OnConferenceRecordingAvailable registers fn for conferenceRecordingAvailable callbacks
+func (r *CallbackRouter) OnConferenceRecordingAvailable(fn func(ctx context.Context, event *ConferenceRecordingAvailableEvent) (bxml.Response, error)) {
+	r.Handle("conferenceRecordingAvailable", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*ConferenceRecordingAvailableEvent))
+	})
+}
+
+// OnDisconnect registers fn for disconnect callbacks; any BXML returned is ignored by Bandwidth
+func (r *CallbackRouter) OnDisconnect(fn func(ctx context.Context, event *DisconnectEvent) (bxml.Response, error)) {
+	r.Handle("disconnect", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*DisconnectEvent))
+	})
+}
+
+// OnDtmf registers fn for dtmf callbacks sent by <StartGather>
+func (r *CallbackRouter) OnDtmf(fn func(ctx context.Context, event *DtmfEvent) (bxml.Response, error)) {
+	r.Handle("dtmf", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*DtmfEvent))
+	})
+}
+
+// OnGather registers fn for gather callbacks
+func (r *CallbackRouter) OnGather(fn func(ctx context.Context, event *GatherEvent) (bxml.Response, error)) {
+	r.Handle("gather", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*GatherEvent))
+	})
+}
+
+// OnInitiate registers fn for initiate callbacks
+func (r *CallbackRouter) OnInitiate(fn func(ctx context.Context, event *InitiateEvent) (bxml.Response, error)) {
+	r.Handle("initiate", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*InitiateEvent))
+	})
+}
+
+// OnMachineDetectionComplete registers fn for machineDetectionComplete callbacks
+func (r *CallbackRouter) OnMachineDetectionComplete(fn func(ctx context.Context, event *MachineDetectionCompleteEvent) (bxml.Response, error)) {
+	r.Handle("machineDetectionComplete", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*MachineDetectionCompleteEvent))
+	})
+}
+
+// OnRecordComplete registers fn for recordComplete callbacks
+func (r *CallbackRouter) OnRecordComplete(fn func(ctx context.Context, event *RecordCompleteEvent) (bxml.Response, error)) {
+	r.Handle("recordComplete", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*RecordCompleteEvent))
+	})
+}
+
+// OnRecordingAvailable registers fn for recordingAvailable callbacks
+func (r *CallbackRouter) OnRecordingAvailable(fn func(ctx context.Context, event *RecordingAvailableEvent) (bxml.Response, error)) {
+	r.Handle("recordingAvailable", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*RecordingAvailableEvent))
+	})
+}
+
+// OnRedirect registers fn for redirect callbacks
+func (r *CallbackRouter) OnRedirect(fn func(ctx context.Context, event *RedirectEvent) (bxml.Response, error)) {
+	r.Handle("redirect", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*RedirectEvent))
+	})
+}
+
+// OnStreamStarted registers fn for streamStarted callbacks
+func (r *CallbackRouter) OnStreamStarted(fn func(ctx context.Context, event *StreamStartedEvent) (bxml.Response, error)) {
+	r.Handle("streamStarted", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*StreamStartedEvent))
+	})
+}
+
+// OnStreamStopped registers fn for streamStopped callbacks
+func (r *CallbackRouter) OnStreamStopped(fn func(ctx context.Context, event *StreamStoppedEvent) (bxml.Response, error)) {
+	r.Handle("streamStopped", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*StreamStoppedEvent))
+	})
+}
+
+// OnStreamRejected registers fn for streamRejected callbacks
+func (r *CallbackRouter) OnStreamRejected(fn func(ctx context.Context, event *StreamRejectedEvent) (bxml.Response, error)) {
+	r.Handle("streamRejected", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*StreamRejectedEvent))
+	})
+}
+
+// OnTranscription registers fn for real time transcription callbacks sent by <StartTranscription>
+func (r *CallbackRouter) OnTranscription(fn func(ctx context.Context, event *RealTimeTranscriptionEvent) (bxml.Response, error)) {
+	r.Handle("transcription", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*RealTimeTranscriptionEvent))
+	})
+}
+
+// OnTranscriptionAvailable registers fn for transcriptionAvailable callbacks
+func (r *CallbackRouter) OnTranscriptionAvailable(fn func(ctx context.Context, event *TranscriptionAvailableEvent) (bxml.Response, error)) {
+	r.Handle("transcriptionAvailable", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*TranscriptionAvailableEvent))
+	})
+}
+
+// OnTransferAnswer registers fn for transferAnswer callbacks
+func (r *CallbackRouter) OnTransferAnswer(fn func(ctx context.Context, event *TransferAnswerEvent) (bxml.Response, error)) {
+	r.Handle("transferAnswer", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*TransferAnswerEvent))
+	})
+}
+
+// OnTransferComplete registers fn for transferComplete callbacks
+func (r *CallbackRouter) OnTransferComplete(fn func(ctx context.Context, event *TransferCompleteEvent) (bxml.Response, error)) {
+	r.Handle("transferComplete", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*TransferCompleteEvent))
+	})
+}
+
+// OnTransferDisconnect registers fn for transferDisconnect callbacks
+func (r *CallbackRouter) OnTransferDisconnect(fn func(ctx context.Context, event *TransferDisconnectEvent) (bxml.Response, error)) {
+	r.Handle("transferDisconnect", func(ctx context.Context, event Event) (bxml.Response, error) {
+		return fn(ctx, event.(*TransferDisconnectEvent))
+	})
+}
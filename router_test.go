@@ -0,0 +1,104 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+func serveFile(t *testing.T, h http.Handler, filename string) *httptest.ResponseRecorder {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer f.Close()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", f))
+	return w
+}
+
+func TestCallbackRouter(t *testing.T) {
+	router := NewCallbackRouter()
+	router.OnAnswer(func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		return bxml.NewResponse().Speak("hello " + event.Tag).Hangup(), nil
+	})
+
+	w := serveFile(t, router, "testdata/answer.json")
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := w.Header().Get("Content-Type"), bxml.ContentType; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := w.Body.String(), "hello example-tag"; !strings.Contains(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("unhandled", func(t *testing.T) {
+		w := serveFile(t, router, "testdata/disconnect.json")
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := w.Body.Len(), 0; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader(`{"eventType":"unknown"}`)))
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks", strings.NewReader(`{`)))
+		if got, want := w.Code, http.StatusBadRequest; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestCallbackRouterError(t *testing.T) {
+	failed := func(ctx context.Context, event *AnswerEvent) (bxml.Response, error) {
+		return bxml.Response{}, errors.New("boom")
+	}
+
+	t.Run("no fallback", func(t *testing.T) {
+		var got error
+		router := NewCallbackRouter(WithErrorHandler(func(ctx context.Context, event Event, err error) {
+			got = err
+		}))
+		router.OnAnswer(failed)
+
+		w := serveFile(t, router, "testdata/answer.json")
+		if got, want := w.Code, http.StatusInternalServerError; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got == nil {
+			t.Fatalf("got nil; want error")
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		router := NewCallbackRouter(WithFallbackResponse(bxml.NewResponse().Speak("sorry").Hangup()))
+		router.OnAnswer(failed)
+
+		w := serveFile(t, router, "testdata/answer.json")
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := w.Body.String(), "sorry"; !strings.Contains(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
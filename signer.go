@@ -0,0 +1,171 @@
+package bandwidth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// query parameters added by URLSigner
+const (
+	signatureParam = "bw_sig"
+	expiresParam   = "bw_exp"
+	keyIdParam     = "bw_kid"
+)
+
+var (
+	// ErrURLExpired is returned when a signed url is used after it expires
+	ErrURLExpired = errors.New("url expired")
+	// ErrInvalidURLSignature is returned when a url is unsigned, or its signature does not match
+	ErrInvalidURLSignature = errors.New("invalid url signature")
+)
+
+type signerOptions struct {
+	ttl  time.Duration
+	keys map[string][]byte
+}
+
+// SignerOption configures a URLSigner
+type SignerOption func(*signerOptions)
+
+// WithSignatureTTL sets how long signed urls are valid; defaults to 24h
+func WithSignatureTTL(d time.Duration) SignerOption {
+	return func(o *signerOptions) {
+		o.ttl = d
+	}
+}
+
+// WithVerificationKey accepts urls signed with a previous key.  Use while rotating keys so urls
+// issued before the rotation remain valid until they expire.
+func WithVerificationKey(keyId string, key []byte) SignerOption {
+	return func(o *signerOptions) {
+		o.keys[keyId] = append([]byte(nil), key...)
+	}
+}
+
+// URLSigner signs callback urls, such as AnswerUrl, RedirectUrl, or GatherUrl, so they cannot be
+// altered or replayed after they expire.  The signature covers the path and query of the url,
+// but not the scheme or host, so urls remain valid behind proxies that rewrite the host.
+//
+//	signer := bandwidth.NewURLSigner("2020-06", key)
+//	answerUrl, err := signer.Sign("https://example.com/answer?customer=1234")
+//	...
+//	http.Handle("/", signer.Middleware(router))
+//
+// Urls must be absolute or begin with /; Bandwidth resolves other relative urls against the
+// url of the document, so the path it requests would not match the path signed.
+type URLSigner struct {
+	keyId   string
+	options signerOptions
+	now     func() time.Time
+}
+
+// NewURLSigner returns a URLSigner that signs with key, identified by keyId
+func NewURLSigner(keyId string, key []byte, opts ...SignerOption) *URLSigner {
+	options := signerOptions{
+		ttl:  24 * time.Hour,
+		keys: map[string][]byte{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.keys[keyId] = append([]byte(nil), key...)
+
+	return &URLSigner{
+		keyId:   keyId,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Sign returns rawurl with an expiry and signature added to its query
+func (s *URLSigner) Sign(rawurl string) (string, error) {
+	return s.SignExpires(rawurl, s.now().Add(s.options.ttl))
+}
+
+// SignExpires returns rawurl with the given expiry and a signature added to its query
+func (s *URLSigner) SignExpires(rawurl string, expires time.Time) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse url, %v: %w", rawurl, err)
+	}
+	if !u.IsAbs() && !strings.HasPrefix(u.Path, "/") {
+		return "", fmt.Errorf("unable to sign relative url, %v: url must be absolute or begin with /", rawurl)
+	}
+
+	query := u.Query()
+	query.Del(signatureParam)
+	query.Set(expiresParam, strconv.FormatInt(expires.Unix(), 10))
+	query.Set(keyIdParam, s.keyId)
+	query.Set(signatureParam, s.sign(s.options.keys[s.keyId], u.EscapedPath(), query))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Verify returns nil if u carries a valid, unexpired signature
+func (s *URLSigner) Verify(u *url.URL) error {
+	query := u.Query()
+	signature := query.Get(signatureParam)
+	if signature == "" {
+		return fmt.Errorf("unable to verify url, %v: %w", u.Path, ErrInvalidURLSignature)
+	}
+
+	key, ok := s.options.keys[query.Get(keyIdParam)]
+	if !ok {
+		return fmt.Errorf("unable to verify url, %v: unknown key id, %v: %w", u.Path, query.Get(keyIdParam), ErrInvalidURLSignature)
+	}
+
+	query.Del(signatureParam)
+	want := s.sign(key, u.EscapedPath(), query)
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return fmt.Errorf("unable to verify url, %v: %w", u.Path, ErrInvalidURLSignature)
+	}
+
+	expires, err := strconv.ParseInt(query.Get(expiresParam), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to verify url, %v: %w", u.Path, ErrInvalidURLSignature)
+	}
+	if s.now().After(time.Unix(expires, 0)) {
+		return fmt.Errorf("unable to verify url, %v: %w", u.Path, ErrURLExpired)
+	}
+	return nil
+}
+
+// Middleware returns a handler that responds 403 to requests without a valid signature and
+// otherwise calls next.  The url is verified as it was received, in req.RequestURI, so the
+// middleware may sit inside handlers that rewrite req.URL such as http.StripPrefix.
+func (s *URLSigner) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u := req.URL
+		if req.RequestURI != "" {
+			received, err := url.ParseRequestURI(req.RequestURI)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			u = received
+		}
+		if err := s.Verify(u); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// sign returns the signature of the path and query; query.Encode sorts the parameters so the
+// signature does not depend on their order
+func (s *URLSigner) sign(key []byte, path string, query url.Values) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(path))
+	mac.Write([]byte("?"))
+	mac.Write([]byte(query.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,113 @@
+package bandwidth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	signer := NewURLSigner("k2", []byte("new"), WithVerificationKey("k1", []byte("old")), WithSignatureTTL(time.Hour))
+	signer.now = func() time.Time { return now }
+
+	old := NewURLSigner("k1", []byte("old"))
+	old.now = signer.now
+	retired := NewURLSigner("k0", []byte("retired"))
+	retired.now = signer.now
+
+	sign := func(s *URLSigner, rawurl string) string {
+		signed, err := s.Sign(rawurl)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		return signed
+	}
+
+	signed := sign(signer, "https://example.com/answer?customer=1234")
+	u, _ := url.Parse(signed)
+	if got, want := u.Query().Get("customer"), "1234"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	testCases := map[string]struct {
+		URL   string
+		After time.Duration
+		Want  error
+	}{
+		"ok":          {URL: signed},
+		"rotated key": {URL: sign(old, "https://example.com/answer")},
+		"path only":   {URL: sign(signer, "/gather?menu=main")},
+		"expired":     {URL: signed, After: 2 * time.Hour, Want: ErrURLExpired},
+		"altered":     {URL: strings.Replace(signed, "customer=1234", "customer=9999", 1), Want: ErrInvalidURLSignature},
+		"moved":       {URL: strings.Replace(signed, "/answer", "/admin", 1), Want: ErrInvalidURLSignature},
+		"retired key": {URL: sign(retired, "https://example.com/answer"), Want: ErrInvalidURLSignature},
+		"unsigned":    {URL: "https://example.com/answer?customer=1234", Want: ErrInvalidURLSignature},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			signer.now = func() time.Time { return now.Add(tc.After) }
+			defer func() { signer.now = func() time.Time { return now } }()
+
+			u, err := url.Parse(tc.URL)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if err := signer.Verify(u); !errors.Is(err, tc.Want) {
+				t.Fatalf("got %v; want %v", err, tc.Want)
+			}
+		})
+	}
+
+	if _, err := signer.Sign("gather"); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
+
+func TestURLSignerMiddleware(t *testing.T) {
+	signer := NewURLSigner("k1", []byte("secret"))
+	h := signer.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	signed, err := signer.Sign("/answer?customer=1234")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	for target, want := range map[string]int{
+		signed:                  http.StatusNoContent,
+		"/answer?customer=1234": http.StatusForbidden,
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, nil))
+		if got := w.Code; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestURLSignerMiddlewareStripPrefix(t *testing.T) {
+	signer := NewURLSigner("k1", []byte("secret"))
+	h := signer.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux := http.NewServeMux()
+	mux.Handle("/callbacks/", http.StripPrefix("/callbacks", h))
+
+	signed, err := signer.Sign("/callbacks/answer?customer=1234")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, signed, nil))
+	if got, want := w.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/savaki/bandwidth/bxml"
+	"github.com/savaki/bandwidth/flowgraph"
+)
+
+// probes are the digits pressed at each single digit <Gather>; an empty string is a timeout
+var probes = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "0", "*", "#", ""}
+
+// Crawl explores every document reachable from the answer url and returns the call flow as a
+// graph.  Each distinct document is a node labelled with its prompts.  Single digit gathers are
+// probed with every key and a timeout; longer gathers with a run of 1s labelled "digits".
+// Redirects and recordings are followed, and transfers, forwards, bridges, conferences, and
+// hangups become end nodes.  The number of documents is limited by WithMaxSteps.
+func (s *Simulator) Crawl(ctx context.Context, script Script) (flowgraph.Graph, error) {
+	if script.CallId == "" {
+		script.CallId = "c-simulator"
+	}
+	startTime := s.options.startTime
+	if startTime.IsZero() {
+		startTime = time.Now().UTC()
+	}
+
+	c := &call{
+		ctx:    ctx,
+		sim:    s,
+		script: script,
+		result: &Result{Tag: script.Tag},
+		start:  startTime,
+		now:    startTime,
+	}
+	cr := &crawler{call: c, seen: map[string]string{}}
+
+	verbs, base, err := c.answer()
+	if err != nil {
+		return flowgraph.Graph{}, err
+	}
+	if _, err := cr.visit(verbs, base, script.Tag); err != nil {
+		return cr.graph, err
+	}
+	return cr.graph, nil
+}
+
+type crawler struct {
+	call  *call
+	graph flowgraph.Graph
+	seen  map[string]string // seen maps a document to the id of its node
+}
+
+// visit adds the node for a document, and the nodes reachable from it, returning its id
+func (cr *crawler) visit(verbs []bxml.Verb, base *url.URL, tag string) (string, error) {
+	if err := cr.call.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	doc, err := bxml.NewResponse(verbs...).Bytes()
+	if err != nil {
+		return "", fmt.Errorf("unable to encode document from %v: %w", base, err)
+	}
+	key := base.String() + "\n" + string(doc)
+	if id, ok := cr.seen[key]; ok {
+		return id, nil
+	}
+	if len(cr.seen) >= cr.call.sim.options.maxSteps {
+		return "", fmt.Errorf("unable to crawl %v after %v documents: %w", base, len(cr.seen), ErrTooManySteps)
+	}
+
+	id := fmt.Sprintf("step%v", len(cr.seen))
+	cr.seen[key] = id
+
+	var lines []string
+	addNode := func() {
+		cr.graph.AddNode(flowgraph.Node{Id: id, Label: strings.Join(lines, "\n")})
+	}
+	follow := func(label string, next []bxml.Verb, nextBase *url.URL) error {
+		child, err := cr.visit(next, nextBase, tag)
+		if err != nil {
+			return err
+		}
+		cr.graph.AddEdge(flowgraph.Edge{From: id, To: child, Label: label})
+		return nil
+	}
+	end := func(label string) (string, error) {
+		addNode()
+		cr.graph.AddNode(flowgraph.Node{Id: "end:" + label, Label: label, Kind: flowgraph.KindEnd})
+		cr.graph.AddEdge(flowgraph.Edge{From: id, To: "end:" + label})
+		return id, nil
+	}
+
+	lines = append(lines, base.Path)
+	for _, verb := range verbs {
+		cr.call.result.Tag = tag
+
+		switch v := verb.(type) {
+		case bxml.SpeakSentence:
+			lines = append(lines, v.Text())
+
+		case bxml.PlayAudio:
+			lines = append(lines, "play "+v.AudioUri)
+
+		case bxml.Tag:
+			tag = v.Value
+
+		case bxml.Gather:
+			for _, prompt := range v.Prompts {
+				switch p := prompt.(type) {
+				case bxml.SpeakSentence:
+					lines = append(lines, p.Text())
+				case bxml.PlayAudio:
+					lines = append(lines, "play "+p.AudioUri)
+				}
+			}
+			if v.GatherUrl == "" {
+				continue
+			}
+
+			addNode()
+			digits := probes
+			if v.MaxDigits > 1 {
+				digits = []string{strings.Repeat("1", v.MaxDigits), ""}
+			}
+			for _, d := range digits {
+				label := d
+				switch {
+				case d == "":
+					label = "timeout"
+				case len(d) > 1:
+					label = "digits"
+				}
+				next, nextBase, err := cr.call.gathered(v, base, d, "")
+				if err != nil {
+					return "", err
+				}
+				if err := follow(label, next, nextBase); err != nil {
+					return "", err
+				}
+			}
+			return id, nil
+
+		case bxml.Redirect:
+			addNode()
+			next, nextBase, err := cr.call.redirect(v, base)
+			if err != nil {
+				return "", err
+			}
+			return id, follow("redirect", next, nextBase)
+
+		case bxml.Record:
+			lines = append(lines, "record")
+			if v.RecordCompleteUrl == "" {
+				continue
+			}
+			addNode()
+			next, nextBase, err := cr.call.record(v, base)
+			if err != nil {
+				return "", err
+			}
+			return id, follow("recorded", next, nextBase)
+
+		case bxml.Hangup:
+			return end("Hangup")
+
+		case bxml.Forward:
+			return end("Forward " + v.To)
+
+		case bxml.Transfer:
+			var targets []string
+			for _, n := range v.PhoneNumbers {
+				targets = append(targets, n.Number)
+			}
+			for _, u := range v.SipUris {
+				targets = append(targets, u.Uri)
+			}
+			return end("Transfer " + strings.Join(targets, ", "))
+
+		case bxml.Bridge:
+			return end("Bridge " + v.TargetCall)
+
+		case bxml.Conference:
+			return end("Conference " + v.Name)
+		}
+	}
+
+	// Bandwidth hangs up once a document runs out of verbs
+	return end("Hangup")
+}
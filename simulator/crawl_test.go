@@ -0,0 +1,71 @@
+package simulator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/savaki/bandwidth/flowgraph"
+)
+
+func TestCrawl(t *testing.T) {
+	sim := New("/answer", WithHandler(newIVR()))
+	g, err := sim.Crawl(context.Background(), Script{})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	find := func(prefix string) flowgraph.Node {
+		for _, n := range g.Nodes {
+			if strings.HasPrefix(n.Label, prefix) {
+				return n
+			}
+		}
+		t.Fatalf("got %v; want node starting with %q", g.Nodes, prefix)
+		return flowgraph.Node{}
+	}
+	edge := func(from, to flowgraph.Node) flowgraph.Edge {
+		for _, e := range g.Edges {
+			if e.From == from.Id && e.To == to.Id {
+				return e
+			}
+		}
+		t.Fatalf("got %v; want edge from %v to %v", g.Edges, from.Id, to.Id)
+		return flowgraph.Edge{}
+	}
+
+	menu := find("/answer\nPress 1")
+	pin := find("/menu\nEnter your pin")
+	message := find("/menu\nLeave a message")
+	redirect := find("/menu")
+	for _, n := range g.Nodes {
+		if n.Label == "/menu" {
+			redirect = n // the document containing only a redirect has no prompts
+		}
+	}
+	thanks := find("/recorded\nThank you")
+
+	if got, want := edge(menu, pin).Label, "1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := edge(menu, message).Label, "2"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := edge(menu, redirect).Label, "3, 4, 5, 6, 7, 8, 9, 0, *, #, timeout"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := edge(redirect, menu).Label, "redirect"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := edge(message, thanks).Label, "recorded"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	pinResult := find("/pin\nYour pin was 1111")
+	if got, want := edge(pin, pinResult).Label, "digits"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if end, ok := g.Node("end:Hangup"); !ok || end.Kind != flowgraph.KindEnd {
+		t.Fatalf("got %v; want hangup end node", end)
+	}
+}
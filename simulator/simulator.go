@@ -0,0 +1,639 @@
+// Package simulator runs BXML call flows offline.  A Simulator plays the part of Bandwidth; it
+// sends callbacks to an application, executes the BXML returned verb by verb, and acts out a
+// scripted caller so an IVR can be tested end to end without dialing a real number.
+//
+//	sim := simulator.New("/answer", simulator.WithHandler(app))
+//	result, err := sim.Run(ctx, simulator.Script{Digits: []string{"1", "1234#"}})
+//	fmt.Println(result.Transcript)
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/savaki/bandwidth"
+	"github.com/savaki/bandwidth/bxml"
+)
+
+// ErrTooManySteps is returned when a call flow executes more verbs than the limit set by
+// WithMaxSteps, typically because it redirects in a loop
+var ErrTooManySteps = errors.New("too many steps")
+
+// Disconnect causes reported by the simulator
+const (
+	CauseHangup  = "hangup"
+	CauseTimeout = "timeout"
+)
+
+// Script describes the call and how the caller behaves
+type Script struct {
+	AccountId     string
+	ApplicationId string
+	CallId        string // CallId - defaults to c-simulator
+	From          string
+	To            string
+	Inbound       bool   // Inbound - start the call with an initiate callback rather than answer
+	Tag           string // Tag - initial tag of the call
+
+	Digits   []string // Digits - pressed for each successive Gather; an empty string times out
+	HangupAt int      // HangupAt - caller hangs up instead of executing this step; 0 never hangs up
+	NoAnswer bool     // NoAnswer - the call is never answered
+}
+
+// Line is a single entry of the transcript
+type Line struct {
+	Step int    // Step - 1 based index of the verb being executed
+	Verb string // Verb - name of the verb e.g. SpeakSentence
+	Text string // Text - what the caller heard or did
+}
+
+func (l Line) String() string {
+	if l.Text == "" {
+		return fmt.Sprintf("%3d %v", l.Step, l.Verb)
+	}
+	return fmt.Sprintf("%3d %v: %v", l.Step, l.Verb, l.Text)
+}
+
+// Transcript records what happened on the call
+type Transcript []Line
+
+func (tt Transcript) String() string {
+	lines := make([]string, 0, len(tt))
+	for _, t := range tt {
+		lines = append(lines, t.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Heard returns what the caller heard in order; the text of each SpeakSentence and the uri of
+// each PlayAudio
+func (tt Transcript) Heard() []string {
+	var heard []string
+	for _, t := range tt {
+		if t.Verb == "SpeakSentence" || t.Verb == "PlayAudio" {
+			heard = append(heard, t.Text)
+		}
+	}
+	return heard
+}
+
+// Result of a simulated call
+type Result struct {
+	Events     []bandwidth.Event // Events - every callback that would have been sent, in order
+	Transcript Transcript
+	Cause      string // Cause - why the call ended; hangup or timeout
+	Tag        string // Tag - tag of the call when it ended
+}
+
+type options struct {
+	handler       http.Handler
+	client        *http.Client
+	disconnectUrl string
+	maxSteps      int
+	startTime     time.Time
+}
+
+// Option configures a Simulator
+type Option func(*options)
+
+// WithHandler sends callbacks to h in process rather than over the network; relative urls are
+// resolved against http://simulator/
+func WithHandler(h http.Handler) Option {
+	return func(o *options) {
+		o.handler = h
+	}
+}
+
+// WithHTTPClient sets the client used to send callbacks over the network
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithDisconnectUrl sends the DisconnectEvent to url; the application's disconnectUrl
+func WithDisconnectUrl(url string) Option {
+	return func(o *options) {
+		o.disconnectUrl = url
+	}
+}
+
+// WithMaxSteps limits the number of verbs a call may execute; defaults to 100
+func WithMaxSteps(n int) Option {
+	return func(o *options) {
+		o.maxSteps = n
+	}
+}
+
+// WithStartTime sets the time the simulated call starts so events are reproducible
+func WithStartTime(t time.Time) Option {
+	return func(o *options) {
+		o.startTime = t
+	}
+}
+
+// Simulator runs call flows starting from an answer url
+type Simulator struct {
+	answerUrl string
+	options   options
+}
+
+// New returns a Simulator whose calls begin by sending the answer (or initiate) callback to
+// answerUrl
+func New(answerUrl string, opts ...Option) *Simulator {
+	options := options{
+		client:   http.DefaultClient,
+		maxSteps: 100,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Simulator{
+		answerUrl: answerUrl,
+		options:   options,
+	}
+}
+
+// Run simulates a single call.  The Result is returned along with any error so the transcript
+// leading up to a failure may be inspected.
+func (s *Simulator) Run(ctx context.Context, script Script) (*Result, error) {
+	if script.CallId == "" {
+		script.CallId = "c-simulator"
+	}
+	startTime := s.options.startTime
+	if startTime.IsZero() {
+		startTime = time.Now().UTC()
+	}
+
+	c := &call{
+		ctx:    ctx,
+		sim:    s,
+		script: script,
+		result: &Result{Tag: script.Tag},
+		start:  startTime,
+		now:    startTime,
+	}
+	return c.result, c.run()
+}
+
+// call holds the state of a single simulated call
+type call struct {
+	ctx    context.Context
+	sim    *Simulator
+	script Script
+	result *Result
+
+	start    time.Time
+	now      time.Time
+	answered bool
+	step     int
+	digits   int // digits is the index of the next entry of script.Digits
+	records  int
+	hungUp   bool
+}
+
+func (c *call) run() error {
+	if c.script.NoAnswer {
+		c.now = c.now.Add(30 * time.Second)
+		return c.disconnect(CauseTimeout)
+	}
+
+	verbs, base, err := c.answer()
+	if err != nil {
+		return err
+	}
+	if err := c.execute(verbs, base); err != nil {
+		return err
+	}
+	if !c.hungUp {
+		// Bandwidth hangs up once a document runs out of verbs
+		return c.disconnect(CauseHangup)
+	}
+	return nil
+}
+
+// answer sends the answer, or initiate, callback and returns the verbs of the answer url
+func (c *call) answer() ([]bxml.Verb, *url.URL, error) {
+	c.answered = true
+	c.now = c.now.Add(time.Second)
+
+	var event bandwidth.Event
+	if c.script.Inbound {
+		event = &bandwidth.InitiateEvent{
+			EventType:     "initiate",
+			EventTime:     c.timestamp(c.now),
+			AccountId:     c.script.AccountId,
+			ApplicationId: c.script.ApplicationId,
+			From:          c.script.From,
+			To:            c.script.To,
+			Direction:     "inbound",
+			CallId:        c.script.CallId,
+			CallUrl:       c.callUrl(),
+			StartTime:     c.timestamp(c.start),
+		}
+	} else {
+		event = &bandwidth.AnswerEvent{
+			EventType:     "answer",
+			EventTime:     c.timestamp(c.now),
+			AccountId:     c.script.AccountId,
+			ApplicationId: c.script.ApplicationId,
+			From:          c.script.From,
+			To:            c.script.To,
+			Direction:     "outbound",
+			CallId:        c.script.CallId,
+			CallUrl:       c.callUrl(),
+			StartTime:     c.timestamp(c.start),
+			AnswerTime:    c.timestamp(c.now),
+			Tag:           c.result.Tag,
+		}
+	}
+
+	return c.callback(c.sim.answerUrl, nil, event, "", "")
+}
+
+// execute runs verbs until the call ends or the verbs are exhausted.  base is the url the verbs
+// were fetched from; relative urls are resolved against it.
+func (c *call) execute(verbs []bxml.Verb, base *url.URL) error {
+	for len(verbs) > 0 {
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+
+		verb := verbs[0]
+		verbs = verbs[1:]
+
+		c.step++
+		if c.step > c.sim.options.maxSteps {
+			return fmt.Errorf("unable to complete call, %v, after %v steps: %w", c.script.CallId, c.sim.options.maxSteps, ErrTooManySteps)
+		}
+		if c.step == c.script.HangupAt {
+			c.log(verb, "caller hung up")
+			return c.disconnect(CauseHangup)
+		}
+		c.now = c.now.Add(time.Second)
+
+		switch v := verb.(type) {
+		case bxml.SpeakSentence:
+			c.log(v, v.Text())
+
+		case bxml.PlayAudio:
+			c.log(v, v.AudioUri)
+
+		case bxml.Pause:
+			c.log(v, fmt.Sprintf("%vs", v.Duration))
+			c.now = c.now.Add(time.Duration(v.Duration) * time.Second)
+
+		case bxml.Ring:
+			c.log(v, fmt.Sprintf("%vs", v.Duration))
+			c.now = c.now.Add(time.Duration(v.Duration) * time.Second)
+
+		case bxml.SendDtmf:
+			c.log(v, v.Digits)
+
+		case bxml.Tag:
+			c.result.Tag = v.Value
+			c.log(v, v.Value)
+
+		case bxml.Gather:
+			next, nextBase, err := c.gather(v, base)
+			if err != nil {
+				return err
+			}
+			// the document returned by the gatherUrl replaces the remaining verbs
+			if v.GatherUrl != "" {
+				return c.execute(next, nextBase)
+			}
+
+		case bxml.Record:
+			next, nextBase, err := c.record(v, base)
+			if err != nil {
+				return err
+			}
+			if v.RecordCompleteUrl != "" {
+				return c.execute(next, nextBase)
+			}
+
+		case bxml.Redirect:
+			c.log(v, v.RedirectUrl)
+			next, nextBase, err := c.redirect(v, base)
+			if err != nil {
+				return err
+			}
+			return c.execute(next, nextBase)
+
+		case bxml.Hangup:
+			c.log(v, "")
+			return c.disconnect(CauseHangup)
+
+		case bxml.Forward:
+			c.log(v, v.To)
+			return c.disconnect(CauseHangup)
+
+		case bxml.Transfer:
+			var targets []string
+			for _, n := range v.PhoneNumbers {
+				targets = append(targets, n.Number)
+			}
+			for _, u := range v.SipUris {
+				targets = append(targets, u.Uri)
+			}
+			c.log(v, strings.Join(targets, ", "))
+			return c.disconnect(CauseHangup)
+
+		case bxml.Bridge:
+			c.log(v, v.TargetCall)
+			return c.disconnect(CauseHangup)
+
+		case bxml.Conference:
+			c.log(v, v.Name)
+			return c.disconnect(CauseHangup)
+
+		default:
+			c.log(v, "")
+		}
+	}
+	return nil
+}
+
+// gather plays the prompts and presses the caller's next digits.  Returns the verbs of the
+// gatherUrl, if any.
+func (c *call) gather(v bxml.Gather, base *url.URL) ([]bxml.Verb, *url.URL, error) {
+	var digits string
+	if c.digits < len(c.script.Digits) {
+		digits = c.script.Digits[c.digits]
+		c.digits++
+	}
+
+	repeat := v.RepeatCount
+	if repeat < 1 {
+		repeat = 1
+	}
+	if digits != "" {
+		repeat = 1 // pressing a digit interrupts the prompts
+	}
+	for i := 0; i < repeat; i++ {
+		for _, prompt := range v.Prompts {
+			switch p := prompt.(type) {
+			case bxml.SpeakSentence:
+				c.log(p, p.Text())
+			case bxml.PlayAudio:
+				c.log(p, p.AudioUri)
+			}
+		}
+	}
+
+	var terminatingDigit string
+	if i := strings.IndexAny(digits, v.TerminatingDigits); v.TerminatingDigits != "" && i >= 0 {
+		terminatingDigit = digits[i : i+1]
+		digits = digits[:i]
+	}
+	if v.MaxDigits > 0 && len(digits) > v.MaxDigits {
+		digits = digits[:v.MaxDigits]
+	}
+
+	if digits == "" && terminatingDigit == "" {
+		c.log(v, "timeout")
+		c.now = c.now.Add(time.Duration(v.FirstDigitTimeout) * time.Second)
+	} else {
+		c.log(v, "pressed "+digits+terminatingDigit)
+	}
+
+	if v.GatherUrl == "" {
+		return nil, nil, nil
+	}
+
+	return c.gathered(v, base, digits, terminatingDigit)
+}
+
+// gathered sends the GatherEvent for the digits pressed and returns the verbs of the gatherUrl
+func (c *call) gathered(v bxml.Gather, base *url.URL, digits, terminatingDigit string) ([]bxml.Verb, *url.URL, error) {
+	event := &bandwidth.GatherEvent{
+		EventType:        "gather",
+		EventTime:        c.timestamp(c.now),
+		AccountId:        c.script.AccountId,
+		ApplicationId:    c.script.ApplicationId,
+		From:             c.script.From,
+		To:               c.script.To,
+		Direction:        c.direction(),
+		CallId:           c.script.CallId,
+		CallUrl:          c.callUrl(),
+		StartTime:        c.timestamp(c.start),
+		AnswerTime:       c.timestamp(c.start.Add(time.Second)),
+		Tag:              c.tag(v.Tag),
+		Digits:           digits,
+		TerminatingDigit: terminatingDigit,
+	}
+	return c.callback(v.GatherUrl, base, event, v.Username, v.Password)
+}
+
+// redirect sends the RedirectEvent and returns the verbs of the redirectUrl
+func (c *call) redirect(v bxml.Redirect, base *url.URL) ([]bxml.Verb, *url.URL, error) {
+	event := &bandwidth.RedirectEvent{
+		EventType:     "redirect",
+		EventTime:     c.timestamp(c.now),
+		AccountId:     c.script.AccountId,
+		ApplicationId: c.script.ApplicationId,
+		From:          c.script.From,
+		To:            c.script.To,
+		Direction:     c.direction(),
+		CallId:        c.script.CallId,
+		CallUrl:       c.callUrl(),
+		StartTime:     c.timestamp(c.start),
+		AnswerTime:    c.timestamp(c.start.Add(time.Second)),
+		Tag:           c.tag(v.Tag),
+	}
+	return c.callback(v.RedirectUrl, base, event, v.Username, v.Password)
+}
+
+// record simulates a recording of maxDuration seconds, or 10 seconds when unset
+func (c *call) record(v bxml.Record, base *url.URL) ([]bxml.Verb, *url.URL, error) {
+	duration := v.MaxDuration
+	if duration == 0 {
+		duration = 10
+	}
+	fileFormat := v.FileFormat
+	if fileFormat == "" {
+		fileFormat = "wav"
+	}
+
+	c.records++
+	recordingId := fmt.Sprintf("r-%v-%v", c.script.CallId, c.records)
+	started := c.now
+	c.now = c.now.Add(time.Duration(duration) * time.Second)
+	c.log(v, recordingId)
+
+	if v.RecordCompleteUrl == "" {
+		return nil, nil, nil
+	}
+
+	event := &bandwidth.RecordCompleteEvent{
+		EventType:     "recordComplete",
+		EventTime:     c.timestamp(c.now),
+		AccountId:     c.script.AccountId,
+		ApplicationId: c.script.ApplicationId,
+		From:          c.script.From,
+		To:            c.script.To,
+		Direction:     c.direction(),
+		CallId:        c.script.CallId,
+		RecordingId:   recordingId,
+		CallUrl:       c.callUrl(),
+		MediaUrl:      c.callUrl() + "/recordings/" + recordingId + "/media",
+		AnswerTime:    c.timestamp(c.start.Add(time.Second)),
+		StartTime:     c.timestamp(started),
+		EndTime:       c.timestamp(c.now),
+		Duration:      fmt.Sprintf("PT%vS", duration),
+		Channels:      "1",
+		FileFormat:    fileFormat,
+		Tag:           c.tag(v.Tag),
+	}
+	return c.callback(v.RecordCompleteUrl, base, event, v.Username, v.Password)
+}
+
+func (c *call) disconnect(cause string) error {
+	c.hungUp = true
+	c.result.Cause = cause
+
+	event := &bandwidth.DisconnectEvent{
+		EventType:     "disconnect",
+		EventTime:     c.timestamp(c.now),
+		AccountId:     c.script.AccountId,
+		ApplicationId: c.script.ApplicationId,
+		From:          c.script.From,
+		To:            c.script.To,
+		Direction:     c.direction(),
+		CallId:        c.script.CallId,
+		CallUrl:       c.callUrl(),
+		StartTime:     c.timestamp(c.start),
+		EndTime:       c.timestamp(c.now),
+		Cause:         cause,
+		Tag:           c.result.Tag,
+	}
+	if c.answered {
+		event.AnswerTime = c.timestamp(c.start.Add(time.Second))
+	}
+
+	if c.sim.options.disconnectUrl == "" {
+		c.result.Events = append(c.result.Events, event)
+		return nil
+	}
+	// the response to a disconnect callback is ignored
+	_, _, err := c.send(c.sim.options.disconnectUrl, nil, event, "", "")
+	return err
+}
+
+// callback sends the event to rawUrl and parses the BXML returned.  Returns the verbs and the url
+// they were fetched from.
+func (c *call) callback(rawUrl string, base *url.URL, event bandwidth.Event, username, password string) ([]bxml.Verb, *url.URL, error) {
+	data, target, err := c.send(rawUrl, base, event, username, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, target, nil
+	}
+
+	verbs, err := bxml.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse BXML from %v: %w", target, err)
+	}
+	return verbs, target, nil
+}
+
+// send records the event and posts it to rawUrl, resolved against base.  Returns the response body
+// and the resolved url.
+func (c *call) send(rawUrl string, base *url.URL, event bandwidth.Event, username, password string) ([]byte, *url.URL, error) {
+	c.result.Events = append(c.result.Events, event)
+
+	if base == nil {
+		base = &url.URL{Scheme: "http", Host: "simulator", Path: "/"}
+	}
+	target, err := base.Parse(rawUrl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse callback url, %v: %w", rawUrl, err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode callback: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create callback request, %v: %w", target, err)
+	}
+	req = req.WithContext(c.ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	var (
+		status int
+		data   []byte
+	)
+	if h := c.sim.options.handler; h != nil {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		status, data = w.Code, w.Body.Bytes()
+	} else {
+		resp, err := c.sim.options.client.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to send callback to %v: %w", target, err)
+		}
+		defer resp.Body.Close()
+
+		buf := bytes.NewBuffer(nil)
+		if _, err := buf.ReadFrom(resp.Body); err != nil {
+			return nil, nil, fmt.Errorf("unable to read callback response from %v: %w", target, err)
+		}
+		status, data = resp.StatusCode, buf.Bytes()
+	}
+
+	if status < 200 || status >= 300 {
+		return nil, nil, fmt.Errorf("unable to send callback to %v: got status %v", target, status)
+	}
+	return data, target, nil
+}
+
+func (c *call) log(verb bxml.Verb, text string) {
+	name := fmt.Sprintf("%T", verb)
+	name = name[strings.LastIndex(name, ".")+1:]
+	c.result.Transcript = append(c.result.Transcript, Line{Step: c.step, Verb: name, Text: text})
+}
+
+// tag returns the tag of a callback.  As with Bandwidth, a verb's tag attribute replaces the
+// tag of the call for this and all future callbacks.
+func (c *call) tag(override string) string {
+	if override != "" {
+		c.result.Tag = override
+	}
+	return c.result.Tag
+}
+
+func (c *call) direction() string {
+	if c.script.Inbound {
+		return "inbound"
+	}
+	return "outbound"
+}
+
+func (c *call) callUrl() string {
+	accountId := c.script.AccountId
+	if accountId == "" {
+		accountId = "simulator"
+	}
+	return "https://voice.bandwidth.com/api/v2/accounts/" + accountId + "/calls/" + c.script.CallId
+}
+
+func (c *call) timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,191 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/savaki/bandwidth"
+	"github.com/savaki/bandwidth/bxml"
+)
+
+// newIVR returns a small menu; press 1 for a pin prompt, 2 to leave a message
+func newIVR() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/answer", bxml.NewResponse().
+		Tag("menu").
+		Gather(bxml.Gather{MaxDigits: 1, GatherUrl: "/menu", RepeatCount: 2}, func(g *bxml.GatherBuilder) {
+			g.Speak("Press 1 for your balance or 2 to leave a message")
+		}).
+		Speak("Goodbye"))
+	mux.HandleFunc("/menu", func(w http.ResponseWriter, req *http.Request) {
+		var event bandwidth.GatherEvent
+		json.NewDecoder(req.Body).Decode(&event)
+
+		switch event.Digits {
+		case "1":
+			bxml.NewResponse().
+				Gather(bxml.Gather{MaxDigits: 4, TerminatingDigits: "#", GatherUrl: "/pin", Tag: "pin"}, func(g *bxml.GatherBuilder) {
+					g.Speak("Enter your pin followed by pound")
+				}).
+				ServeHTTP(w, req)
+		case "2":
+			bxml.NewResponse().
+				Speak("Leave a message after the tone").
+				Record(bxml.Record{MaxDuration: 30, RecordCompleteUrl: "/recorded"}).
+				ServeHTTP(w, req)
+		default:
+			bxml.NewResponse().Redirect("/answer").ServeHTTP(w, req)
+		}
+	})
+	mux.HandleFunc("/pin", func(w http.ResponseWriter, req *http.Request) {
+		var event bandwidth.GatherEvent
+		json.NewDecoder(req.Body).Decode(&event)
+		bxml.NewResponse().Speak("Your pin was "+event.Digits).Hangup().ServeHTTP(w, req)
+	})
+	mux.Handle("/recorded", bxml.NewResponse().Speak("Thank you").Hangup())
+	return mux
+}
+
+func eventTypes(events []bandwidth.Event) []string {
+	var types []string
+	for _, event := range events {
+		data, _ := json.Marshal(event)
+		var v struct{ EventType string }
+		json.Unmarshal(data, &v)
+		types = append(types, v.EventType)
+	}
+	return types
+}
+
+func TestRun(t *testing.T) {
+	sim := New("/answer", WithHandler(newIVR()), WithStartTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	result, err := sim.Run(context.Background(), Script{CallId: "c-123", Digits: []string{"1", "1234#"}})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := []string{
+		"Press 1 for your balance or 2 to leave a message",
+		"Enter your pin followed by pound",
+		"Your pin was 1234",
+	}
+	if got := result.Transcript.Heard(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := eventTypes(result.Events), []string{"answer", "gather", "gather", "disconnect"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	pin := result.Events[2].(*bandwidth.GatherEvent)
+	if got, want := pin.Digits, "1234"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := pin.TerminatingDigit, "#"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := pin.Tag, "pin"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Events[1].(*bandwidth.GatherEvent).Tag, "menu"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	// the gather's tag applies to every later callback
+	if got, want := result.Events[3].(*bandwidth.DisconnectEvent).Tag, "pin"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Tag, "pin"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Cause, CauseHangup; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRunRecord(t *testing.T) {
+	sim := New("/answer", WithHandler(newIVR()))
+	result, err := sim.Run(context.Background(), Script{Digits: []string{"2"}})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := eventTypes(result.Events), []string{"answer", "gather", "recordComplete", "disconnect"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Events[2].(*bandwidth.RecordCompleteEvent).Duration, "PT30S"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Transcript.Heard()[2], "Thank you"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	// a caller that never presses a digit hears the menu repeated and is redirected back to it
+	// until the step limit is reached
+	sim := New("/answer", WithHandler(newIVR()), WithMaxSteps(10))
+	result, err := sim.Run(context.Background(), Script{})
+	if !errors.Is(err, ErrTooManySteps) {
+		t.Fatalf("got %v; want %v", err, ErrTooManySteps)
+	}
+
+	prompt := "Press 1 for your balance or 2 to leave a message"
+	if got, want := result.Transcript.Heard()[:2], []string{prompt, prompt}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := eventTypes(result.Events)[:3], []string{"answer", "gather", "redirect"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := result.Events[1].(*bandwidth.GatherEvent).Digits; got != "" {
+		t.Fatalf("got %v; want no digits", got)
+	}
+}
+
+func TestRunHangupAt(t *testing.T) {
+	sim := New("/answer", WithHandler(newIVR()))
+	result, err := sim.Run(context.Background(), Script{HangupAt: 2})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := eventTypes(result.Events), []string{"answer", "disconnect"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := result.Transcript.Heard(); len(got) != 0 {
+		t.Fatalf("got %v; want nothing heard", got)
+	}
+	if got, want := result.Tag, "menu"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRunNoAnswer(t *testing.T) {
+	var disconnect bandwidth.DisconnectEvent
+	mux := http.NewServeMux()
+	mux.HandleFunc("/disconnect", func(w http.ResponseWriter, req *http.Request) {
+		json.NewDecoder(req.Body).Decode(&disconnect)
+	})
+
+	sim := New("/answer", WithHandler(mux), WithDisconnectUrl("/disconnect"))
+	result, err := sim.Run(context.Background(), Script{CallId: "c-123", NoAnswer: true})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := eventTypes(result.Events), []string{"disconnect"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := disconnect.Cause, CauseTimeout; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := disconnect.CallId, "c-123"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if disconnect.AnswerTime != "" {
+		t.Fatalf("got %v; want no answer time", disconnect.AnswerTime)
+	}
+}
@@ -0,0 +1,108 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Client is the sending side of a media stream; it plays the role of Bandwidth when testing a
+// Server locally
+type Client struct {
+	conn *conn
+}
+
+// Dial connects to the stream server at the ws or wss url
+func Dial(ctx context.Context, url string) (*Client, error) {
+	c, err := dial(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: c}, nil
+}
+
+// Send sends a single message
+func (c *Client) Send(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("unable to encode %v message: %w", message.EventType, err)
+	}
+	return c.SendRaw(data)
+}
+
+// SendRaw sends a single message that has already been encoded
+func (c *Client) SendRaw(data []byte) error {
+	if err := c.conn.WriteMessage(opText, data); err != nil {
+		return fmt.Errorf("unable to send stream message: %w", err)
+	}
+	return nil
+}
+
+// Receive returns the next message sent by the server, typically playAudio.  Returns io.EOF once
+// the server closes the connection.
+func (c *Client) Receive() (Message, error) {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return Message{}, err
+	}
+
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return Message{}, fmt.Errorf("unable to decode stream message: %w", err)
+	}
+	return message, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Replay sends recorded messages, one json message per line, to the stream server at url.  The
+// recording should end with a stop message; Replay waits for the server to close the connection
+// and returns the messages it sent, such as playAudio.
+func Replay(ctx context.Context, url string, r io.Reader) ([]Message, error) {
+	client, err := Dial(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	received := make(chan []Message, 1)
+	go func() {
+		var messages []Message
+		for {
+			message, err := client.Receive()
+			if err != nil {
+				received <- messages
+				return
+			}
+			messages = append(messages, message)
+		}
+	}()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := client.SendRaw(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read recorded messages: %w", err)
+	}
+
+	select {
+	case messages := <-received:
+		return messages, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,354 @@
+// Package stream accepts the media stream websocket connections Bandwidth opens for <StartStream>
+// and decodes the audio of each call.
+//
+//	server := stream.NewServer(func(s *stream.Stream) {
+//		audio := s.Reader(stream.TrackInbound)
+//		analyze(s.CallId, audio)
+//	})
+//	http.Handle("/stream", server)
+package stream
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Tracks of audio sent on a stream
+const (
+	TrackInbound  = "inbound"
+	TrackOutbound = "outbound"
+)
+
+// Encodings of the audio of a track or sent with Play
+const (
+	EncodingPCMU = "audio/PCMU"
+	EncodingPCM  = "audio/PCM"
+)
+
+// Event types of stream messages
+const (
+	EventStart     = "start"
+	EventMedia     = "media"
+	EventStop      = "stop"
+	EventPlayAudio = "playAudio"
+)
+
+// MediaFormat describes the audio of a track
+type MediaFormat struct {
+	Encoding   string `json:"encoding"`
+	SampleRate int    `json:"sampleRate"`
+}
+
+// Track is a single audio track of a stream
+type Track struct {
+	Name        string      `json:"name"`
+	MediaFormat MediaFormat `json:"mediaFormat"`
+}
+
+// Metadata identifies the call and stream; sent with start and stop messages
+type Metadata struct {
+	AccountId  string  `json:"accountId"`
+	CallId     string  `json:"callId"`
+	StreamId   string  `json:"streamId"`
+	StreamName string  `json:"streamName"`
+	Tracks     []Track `json:"tracks"`
+}
+
+// Media is audio sent to a call with a playAudio message
+type Media struct {
+	ContentType string `json:"contentType"`
+	Payload     string `json:"payload"`
+}
+
+// Message is a single json message sent over a stream
+type Message struct {
+	EventType    string            `json:"eventType"`
+	Metadata     *Metadata         `json:"metadata,omitempty"`
+	StreamParams map[string]string `json:"streamParams,omitempty"`
+	Track        string            `json:"track,omitempty"`
+	Payload      string            `json:"payload,omitempty"`
+	Media        *Media            `json:"media,omitempty"`
+}
+
+// Frame is the decoded audio of a single media message
+type Frame struct {
+	CallId string
+	Track  string
+	Audio  []byte
+}
+
+// Handler is called in its own goroutine once a stream has started.  The connection is closed
+// after the stream stops and the handler returns.
+type Handler func(s *Stream)
+
+// Stream is the audio of a single call
+type Stream struct {
+	AccountId  string
+	CallId     string
+	StreamId   string
+	StreamName string
+	Tracks     []Track
+	Params     map[string]string // Params - the <StreamParam> values of the <StartStream>
+
+	conn    *conn
+	buffers map[string]*buffer
+	done    chan struct{}
+}
+
+// Reader returns the decoded audio of the named track.  Reads block until audio arrives and
+// return io.EOF once the stream stops.  Returns nil if the stream has no such track.
+func (s *Stream) Reader(track string) io.Reader {
+	if b, ok := s.buffers[track]; ok {
+		return b
+	}
+	return nil
+}
+
+// Done is closed once the stream stops
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Play sends audio to be played to the call; only bidirectional streams support playback.
+// encoding is one of EncodingPCMU or EncodingPCM.
+func (s *Stream) Play(encoding string, audio []byte) error {
+	message := Message{
+		EventType: EventPlayAudio,
+		Media: &Media{
+			ContentType: encoding,
+			Payload:     base64.StdEncoding.EncodeToString(audio),
+		},
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("unable to encode playAudio message: %w", err)
+	}
+	if err := s.conn.WriteMessage(opText, data); err != nil {
+		return fmt.Errorf("unable to play audio to call, %v: %w", s.CallId, err)
+	}
+	return nil
+}
+
+// Option configures a Server
+type Option func(*options)
+
+type options struct {
+	onFrame    func(s *Stream, f Frame)
+	onError    func(err error)
+	bufferSize int
+}
+
+// WithFrameHandler calls fn with each frame of audio as it arrives, before it is delivered to
+// the track's Reader.  fn is called from the connection's read loop and should not block.
+func WithFrameHandler(fn func(s *Stream, f Frame)) Option {
+	return func(o *options) {
+		o.onFrame = fn
+	}
+}
+
+// WithErrorHandler calls fn with errors from connections that end abnormally
+func WithErrorHandler(fn func(err error)) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// WithBufferSize sets the number of bytes of audio buffered for each track's Reader; defaults
+// to 1MB, about two minutes of PCMU.  When a reader falls behind the oldest audio is discarded.
+func WithBufferSize(n int) Option {
+	return func(o *options) {
+		o.bufferSize = n
+	}
+}
+
+// Server accepts media stream connections
+type Server struct {
+	handler Handler
+	options options
+}
+
+// NewServer returns a Server that calls handler for each stream.  handler may be nil when only
+// a frame handler is used.
+func NewServer(handler Handler, opts ...Option) *Server {
+	options := options{
+		bufferSize: 1 << 20,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Server{
+		handler: handler,
+		options: options,
+	}
+}
+
+// ServeHTTP upgrades the request to a websocket and serves the stream until it stops
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	c, err := upgrade(w, req)
+	if err != nil {
+		s.error(err)
+		return
+	}
+	defer c.Close()
+
+	if err := s.serve(c); err != nil {
+		s.error(err)
+	}
+}
+
+func (s *Server) error(err error) {
+	if s.options.onError != nil {
+		s.options.onError(err)
+	}
+}
+
+func (s *Server) serve(c *conn) error {
+	var (
+		stream *Stream
+		wg     sync.WaitGroup
+	)
+	defer func() {
+		if stream != nil {
+			stream.stop()
+		}
+		wg.Wait()
+	}()
+
+	for {
+		_, data, err := c.ReadMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read stream message: %w", err)
+		}
+
+		var message Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			return fmt.Errorf("unable to decode stream message: %w", err)
+		}
+
+		switch message.EventType {
+		case EventStart:
+			if stream != nil {
+				return fmt.Errorf("unable to start stream, %v: already started", stream.StreamId)
+			}
+			stream = s.start(c, message)
+			if s.handler != nil {
+				wg.Add(1)
+				go func(stream *Stream) {
+					defer wg.Done()
+					s.handler(stream)
+				}(stream)
+			}
+
+		case EventMedia:
+			if stream == nil {
+				return fmt.Errorf("unable to decode media: stream not started")
+			}
+			audio, err := base64.StdEncoding.DecodeString(message.Payload)
+			if err != nil {
+				return fmt.Errorf("unable to decode media for call, %v: %w", stream.CallId, err)
+			}
+			track := message.Track
+			if track == "" {
+				track = TrackInbound
+			}
+			if s.options.onFrame != nil {
+				s.options.onFrame(stream, Frame{CallId: stream.CallId, Track: track, Audio: audio})
+			}
+			if b, ok := stream.buffers[track]; ok {
+				b.Write(audio)
+			}
+
+		case EventStop:
+			return nil
+		}
+	}
+}
+
+func (s *Server) start(c *conn, message Message) *Stream {
+	stream := &Stream{
+		Params:  message.StreamParams,
+		conn:    c,
+		buffers: map[string]*buffer{},
+		done:    make(chan struct{}),
+	}
+	if m := message.Metadata; m != nil {
+		stream.AccountId = m.AccountId
+		stream.CallId = m.CallId
+		stream.StreamId = m.StreamId
+		stream.StreamName = m.StreamName
+		stream.Tracks = m.Tracks
+	}
+	for _, track := range stream.Tracks {
+		stream.buffers[track.Name] = newBuffer(s.options.bufferSize)
+	}
+	if len(stream.buffers) == 0 {
+		stream.buffers[TrackInbound] = newBuffer(s.options.bufferSize)
+	}
+	return stream
+}
+
+func (s *Stream) stop() {
+	for _, b := range s.buffers {
+		b.Close()
+	}
+	close(s.done)
+}
+
+// buffer is a bounded io.Reader fed by the read loop; when full the oldest audio is discarded
+// so a slow reader never blocks the connection
+type buffer struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	size   int
+	closed bool
+}
+
+func newBuffer(size int) *buffer {
+	b := &buffer{size: size}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *buffer) Write(p []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.size; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	b.cond.Broadcast()
+}
+
+func (b *buffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for len(b.data) == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if len(b.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	return n, nil
+}
+
+func (b *buffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.cond.Broadcast()
+}
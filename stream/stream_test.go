@@ -0,0 +1,135 @@
+package stream
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		frames   []Frame
+		inbound  []byte
+		outbound []byte
+		params   map[string]string
+	)
+
+	handler := func(s *Stream) {
+		mu.Lock()
+		params = s.Params
+		mu.Unlock()
+
+		if err := s.Play(EncodingPCMU, []byte{0x7f, 0x7f}); err != nil {
+			t.Errorf("got %v; want nil", err)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, _ := ioutil.ReadAll(s.Reader(TrackOutbound))
+			mu.Lock()
+			outbound = data
+			mu.Unlock()
+		}()
+		data, _ := ioutil.ReadAll(s.Reader(TrackInbound))
+		wg.Wait()
+
+		mu.Lock()
+		inbound = data
+		mu.Unlock()
+	}
+	onFrame := func(s *Stream, f Frame) {
+		mu.Lock()
+		frames = append(frames, f)
+		mu.Unlock()
+	}
+
+	server := httptest.NewServer(NewServer(handler, WithFrameHandler(onFrame)))
+	defer server.Close()
+
+	f, err := os.Open("testdata/call.jsonl")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	received, err := Replay(ctx, url, f)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	callId := "c-2a913f94-2d4e7fcf-4f07-4aa1-bd11-b5e7d2b8f4fd"
+	wantFrames := []Frame{
+		{CallId: callId, Track: TrackInbound, Audio: []byte{0, 1, 2, 3}},
+		{CallId: callId, Track: TrackOutbound, Audio: []byte{0xff}},
+		{CallId: callId, Track: TrackInbound, Audio: []byte{4, 5, 6, 7}},
+	}
+	if !reflect.DeepEqual(frames, wantFrames) {
+		t.Fatalf("got %v; want %v", frames, wantFrames)
+	}
+	if got, want := inbound, []byte{0, 1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := outbound, []byte{0xff}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := params["customer"], "42"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if got, want := len(received), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := received[0].EventType, EventPlayAudio; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := received[0].Media.Payload, "f38="; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestServerRejectsPlainHTTP(t *testing.T) {
+	var got error
+	server := httptest.NewServer(NewServer(nil, WithErrorHandler(func(err error) { got = err })))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 400 {
+		t.Fatalf("got %v; want %v", resp.StatusCode, 400)
+	}
+	if got == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
+
+func TestBufferDiscardsOldest(t *testing.T) {
+	b := newBuffer(4)
+	b.Write([]byte{1, 2, 3})
+	b.Write([]byte{4, 5, 6})
+	b.Close()
+
+	got, _ := ioutil.ReadAll(b)
+	if want := []byte{3, 4, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,371 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// websocketGUID is the magic value from RFC 6455 used to compute Sec-WebSocket-Accept
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// maxMessageSize limits the size of a single message; media frames are typically a few kilobytes
+const maxMessageSize = 1 << 20
+
+// maxControlSize limits the payload of close, ping and pong frames, RFC 6455 §5.5
+const maxControlSize = 125
+
+const (
+	closeNormal        = 1000
+	closeProtocolError = 1002
+	closeTooLarge      = 1009
+)
+
+var (
+	errMessageTooLarge = errors.New("websocket message too large")
+	errProtocol        = errors.New("websocket protocol error")
+)
+
+// conn is a minimal RFC 6455 websocket connection supporting the features media streams use;
+// text and binary messages, fragmentation, ping/pong and close
+type conn struct {
+	raw    net.Conn
+	r      *bufio.Reader
+	client bool // client connections mask the frames they send
+
+	mu        sync.Mutex // mu serializes writes
+	closeSent bool       // closeSent is set once a close frame has been written
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+websocketGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerContains(h http.Header, name, value string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgrade completes the websocket handshake for an http request
+func upgrade(w http.ResponseWriter, req *http.Request) (*conn, error) {
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if req.Method != http.MethodGet || !headerContains(req.Header, "Connection", "upgrade") || !headerContains(req.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, fmt.Errorf("unable to upgrade connection: not a websocket request")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("unable to upgrade connection: response does not support hijacking")
+	}
+
+	raw, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("unable to upgrade connection: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("unable to upgrade connection: %w", err)
+	}
+	if err := rw.Flush(); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("unable to upgrade connection: %w", err)
+	}
+
+	return &conn{raw: raw, r: rw.Reader}, nil
+}
+
+// dial opens a client websocket connection to a ws or wss url
+func dial(ctx context.Context, rawURL string) (*conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial %v: %w", rawURL, err)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "wss" {
+			host += ":443"
+		} else {
+			host += ":80"
+		}
+	}
+
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial %v: %w", rawURL, err)
+	}
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		raw = tls.Client(raw, &tls.Config{ServerName: u.Hostname()})
+	default:
+		raw.Close()
+		return nil, fmt.Errorf("unable to dial %v: unsupported scheme, %v", rawURL, u.Scheme)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("unable to dial %v: %w", rawURL, err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+u.Host+u.RequestURI(), nil)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("unable to dial %v: %w", rawURL, err)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(raw); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("unable to dial %v: %w", rawURL, err)
+	}
+
+	r := bufio.NewReader(raw)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("unable to dial %v: %w", rawURL, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		raw.Close()
+		return nil, fmt.Errorf("unable to dial %v: handshake failed with status, %v", rawURL, resp.StatusCode)
+	}
+
+	return &conn{raw: raw, r: r, client: true}, nil
+}
+
+// ReadMessage returns the next text or binary message, answering pings as they arrive.  Returns
+// io.EOF once the peer closes the connection.
+func (c *conn) ReadMessage() (int, []byte, error) {
+	var (
+		opcode  int
+		message []byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			// echo the status code only, once; the peer's reason is not repeated
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			c.writeClose(payload)
+			return 0, nil, io.EOF
+		case opContinuation:
+			if opcode == 0 {
+				return 0, nil, c.fail(closeProtocolError, fmt.Errorf("%w: unexpected continuation frame", errProtocol))
+			}
+		case opText, opBinary:
+			// a new message may not start until a fragmented message is complete, RFC 6455 §5.4
+			if opcode != 0 {
+				return 0, nil, c.fail(closeProtocolError, fmt.Errorf("%w: expected continuation frame", errProtocol))
+			}
+			opcode = op
+		default:
+			return 0, nil, c.fail(closeProtocolError, fmt.Errorf("%w: reserved opcode, %#x", errProtocol, op))
+		}
+
+		if len(message)+len(payload) > maxMessageSize {
+			return 0, nil, c.fail(closeTooLarge, errMessageTooLarge)
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// WriteMessage writes a single unfragmented message
+func (c *conn) WriteMessage(opcode int, data []byte) error {
+	return c.writeFrame(opcode, data)
+}
+
+// Close sends a close frame, unless one has already been sent, and closes the underlying connection
+func (c *conn) Close() error {
+	c.writeClose(closePayload(closeNormal))
+	return c.raw.Close()
+}
+
+// fail closes the connection with the given status code after a protocol violation
+func (c *conn) fail(code int, err error) error {
+	c.writeClose(closePayload(code))
+	c.raw.Close()
+	return err
+}
+
+// writeClose writes a close frame unless one has already been written
+func (c *conn) writeClose(payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+	return c.write(opClose, payload)
+}
+
+func closePayload(code int) []byte {
+	return []byte{byte(code >> 8), byte(code)}
+}
+
+func (c *conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+
+	// clients must mask every frame and servers must not mask any, RFC 6455 §5.1
+	if masked == c.client {
+		return false, 0, nil, c.fail(closeProtocolError, fmt.Errorf("%w: unexpected frame masking", errProtocol))
+	}
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(closeProtocolError, fmt.Errorf("%w: reserved bits set", errProtocol))
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var n [2]byte
+		if _, err := io.ReadFull(c.r, n[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(n[:]))
+	case 127:
+		var n [8]byte
+		if _, err := io.ReadFull(c.r, n[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(n[:])
+	}
+	if opcode >= opClose && (!fin || length > maxControlSize) {
+		return false, 0, nil, c.fail(closeProtocolError, fmt.Errorf("%w: invalid control frame", errProtocol))
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, c.fail(closeTooLarge, errMessageTooLarge)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a data, ping or pong frame; no frames may follow a close frame
+func (c *conn) writeFrame(opcode int, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeSent {
+		return fmt.Errorf("unable to write frame: connection closing")
+	}
+	return c.write(opcode, payload)
+}
+
+// write writes a single frame; callers must hold mu
+func (c *conn) write(opcode int, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|byte(opcode))
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126, byte(n>>8), byte(n))
+	default:
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(n))
+		frame = append(frame, maskBit|127)
+		frame = append(frame, size[:]...)
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_, err := c.raw.Write(frame)
+	return err
+}
@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+// frame returns a client frame with the given first byte, masked with a zero key unless unmasked
+func frame(b0 byte, payload []byte, unmasked bool) []byte {
+	var maskBit byte = 0x80
+	if unmasked {
+		maskBit = 0
+	}
+
+	data := []byte{b0}
+	if n := len(payload); n < 126 {
+		data = append(data, maskBit|byte(n))
+	} else {
+		data = append(data, maskBit|126, byte(n>>8), byte(n))
+	}
+	if !unmasked {
+		data = append(data, 0, 0, 0, 0)
+	}
+	return append(data, payload...)
+}
+
+// serve calls fn with a server conn whose peer sends data, and returns everything the server
+// wrote before closing the connection along with the error fn returned
+func serve(data []byte, fn func(c *conn) error) ([]byte, error) {
+	server, peer := net.Pipe()
+	c := &conn{raw: server, r: bufio.NewReader(server)}
+
+	written := make(chan []byte)
+	go func() {
+		data, _ := ioutil.ReadAll(peer)
+		written <- data
+	}()
+	go peer.Write(data)
+
+	err := fn(c)
+	c.raw.Close()
+	return <-written, err
+}
+
+func readMessage(c *conn) error {
+	_, _, err := c.ReadMessage()
+	return err
+}
+
+func TestRejectsUnmaskedClientFrame(t *testing.T) {
+	written, err := serve(frame(0x80|opText, []byte("hi"), true), readMessage)
+	if !errors.Is(err, errProtocol) {
+		t.Fatalf("got %v; want %v", err, errProtocol)
+	}
+	if want := []byte{0x80 | opClose, 2, 0x03, 0xea}; !bytes.Equal(written, want) {
+		t.Fatalf("got %x; want %x", written, want)
+	}
+}
+
+func TestRejectsMaskedServerFrame(t *testing.T) {
+	client, peer := net.Pipe()
+	c := &conn{raw: client, r: bufio.NewReader(client), client: true}
+	go func() {
+		peer.Write(frame(0x80|opText, []byte("hi"), false))
+		ioutil.ReadAll(peer)
+	}()
+
+	if _, _, err := c.ReadMessage(); !errors.Is(err, errProtocol) {
+		t.Fatalf("got %v; want %v", err, errProtocol)
+	}
+}
+
+func TestRejectsInvalidControlFrames(t *testing.T) {
+	testCases := map[string][]byte{
+		"too large":  frame(0x80|opPing, bytes.Repeat([]byte("x"), maxControlSize+1), false),
+		"fragmented": frame(opPing, []byte("x"), false),
+	}
+
+	for label, data := range testCases {
+		t.Run(label, func(t *testing.T) {
+			written, err := serve(data, readMessage)
+			if !errors.Is(err, errProtocol) {
+				t.Fatalf("got %v; want %v", err, errProtocol)
+			}
+			if want := []byte{0x80 | opClose, 2, 0x03, 0xea}; !bytes.Equal(written, want) {
+				t.Fatalf("got %x; want %x", written, want)
+			}
+		})
+	}
+}
+
+func TestRejectsInvalidDataFrames(t *testing.T) {
+	testCases := map[string][]byte{
+		"reserved data opcode":    frame(0x80|0x3, []byte("x"), false),
+		"reserved control opcode": frame(0x80|0xB, []byte("x"), false),
+		"interleaved message":     append(frame(opText, []byte("a"), false), frame(0x80|opText, []byte("b"), false)...),
+		"continuation first":      frame(0x80|opContinuation, []byte("x"), false),
+	}
+
+	for label, data := range testCases {
+		t.Run(label, func(t *testing.T) {
+			written, err := serve(data, readMessage)
+			if !errors.Is(err, errProtocol) {
+				t.Fatalf("got %v; want %v", err, errProtocol)
+			}
+			if want := []byte{0x80 | opClose, 2, 0x03, 0xea}; !bytes.Equal(written, want) {
+				t.Fatalf("got %x; want %x", written, want)
+			}
+		})
+	}
+}
+
+func TestCloseEchoedOnce(t *testing.T) {
+	data := frame(0x80|opClose, []byte{0x03, 0xe8, 'b', 'y', 'e'}, false)
+	written, err := serve(data, func(c *conn) error {
+		err := readMessage(c)
+		c.Close()
+		if err := c.WriteMessage(opText, []byte("late")); err == nil {
+			t.Fatalf("got nil; want error")
+		}
+		return err
+	})
+	if err != io.EOF {
+		t.Fatalf("got %v; want %v", err, io.EOF)
+	}
+	if want := []byte{0x80 | opClose, 2, 0x03, 0xe8}; !bytes.Equal(written, want) {
+		t.Fatalf("got %x; want %x", written, want)
+	}
+}
@@ -0,0 +1,107 @@
+package bandwidth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/savaki/bandwidth/bxml"
+)
+
+// MaxTagLength is the longest tag Bandwidth accepts
+const MaxTagLength = 256
+
+// tagSignatureSize is the number of bytes of the HMAC kept in a tag
+const tagSignatureSize = 16
+
+var (
+	// ErrTagTooLong is returned when an encoded tag exceeds MaxTagLength
+	ErrTagTooLong = errors.New("tag too long")
+	// ErrInvalidTag is returned when a tag is malformed or its signature does not match
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+// TagCodec stores a struct in the tag of a call so per call state is returned with every
+// callback.  Tags are signed with HMAC-SHA256 so state altered by a caller is rejected.  Encoded
+// tags work anywhere a tag is accepted e.g. CreateCallInput, UpdateCallInput, or a BXML verb.
+//
+//	codec := bandwidth.NewTagCodec(key)
+//	tag, err := codec.Encode(State{OrderId: "1234", Step: 2})
+//	...
+//	var state State
+//	err := codec.DecodeEvent(event, &state)
+//
+// The state is base64 encoded json, not encrypted, so it must not contain secrets.
+type TagCodec struct {
+	key []byte
+}
+
+// NewTagCodec returns a TagCodec that signs tags with key
+func NewTagCodec(key []byte) *TagCodec {
+	return &TagCodec{key: append([]byte(nil), key...)}
+}
+
+// Encode returns v, marshaled as json, as a signed tag
+func (c *TagCodec) Encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal tag: %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	tag := payload + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+	if len(tag) > MaxTagLength {
+		return "", fmt.Errorf("unable to encode tag of %v characters, limit is %v: %w", len(tag), MaxTagLength, ErrTagTooLong)
+	}
+	return tag, nil
+}
+
+// Decode verifies the signature of tag and unmarshals it into v
+func (c *TagCodec) Decode(tag string, v interface{}) error {
+	if len(tag) > MaxTagLength {
+		return fmt.Errorf("unable to decode tag: %w", ErrTagTooLong)
+	}
+
+	i := strings.LastIndex(tag, ".")
+	if i < 0 {
+		return fmt.Errorf("unable to decode tag, %q: %w", tag, ErrInvalidTag)
+	}
+	payload := tag[:i]
+	signature, err := base64.RawURLEncoding.DecodeString(tag[i+1:])
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return fmt.Errorf("unable to verify tag, %q: %w", tag, ErrInvalidTag)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return fmt.Errorf("unable to decode tag, %q: %w", tag, ErrInvalidTag)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unable to unmarshal tag: %w", err)
+	}
+	return nil
+}
+
+// DecodeEvent decodes the tag of event into v
+func (c *TagCodec) DecodeEvent(event Event, v interface{}) error {
+	return c.Decode(event.GetTag(), v)
+}
+
+// Verb returns a <Tag> that sets the tag of future callbacks to v
+func (c *TagCodec) Verb(v interface{}) (bxml.Tag, error) {
+	tag, err := c.Encode(v)
+	if err != nil {
+		return bxml.Tag{}, err
+	}
+	return bxml.Tag{Value: tag}, nil
+}
+
+func (c *TagCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)[:tagSignatureSize]
+}
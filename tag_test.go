@@ -0,0 +1,72 @@
+package bandwidth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type tagState struct {
+	OrderId string `json:"o"`
+	Step    int    `json:"s"`
+}
+
+func TestTagCodec(t *testing.T) {
+	codec := NewTagCodec([]byte("secret"))
+
+	tag, err := codec.Encode(tagState{OrderId: "1234", Step: 2})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	event := &GatherEvent{EventType: "gather", Tag: tag}
+	var got tagState
+	if err := codec.DecodeEvent(event, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if want := (tagState{OrderId: "1234", Step: 2}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	verb, err := codec.Verb(tagState{OrderId: "1234", Step: 2})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := verb.Value, tag; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestTagCodecRejects(t *testing.T) {
+	codec := NewTagCodec([]byte("secret"))
+	tag, _ := codec.Encode(tagState{OrderId: "1234"})
+	other, _ := NewTagCodec([]byte("other")).Encode(tagState{OrderId: "1234"})
+
+	// swap the payload for one claiming a different order
+	forged, _ := NewTagCodec([]byte("secret")).Encode(tagState{OrderId: "9999"})
+	forged = forged[:strings.LastIndex(forged, ".")] + tag[strings.LastIndex(tag, "."):]
+
+	testCases := map[string]struct {
+		Tag  string
+		Want error
+	}{
+		"other key": {Tag: other, Want: ErrInvalidTag},
+		"forged":    {Tag: forged, Want: ErrInvalidTag},
+		"plain":     {Tag: "example-tag", Want: ErrInvalidTag},
+		"empty":     {Tag: "", Want: ErrInvalidTag},
+		"too long":  {Tag: strings.Repeat("x", MaxTagLength+1), Want: ErrTagTooLong},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var v tagState
+			if err := codec.Decode(tc.Tag, &v); !errors.Is(err, tc.Want) {
+				t.Fatalf("got %v; want %v", err, tc.Want)
+			}
+		})
+	}
+
+	if _, err := codec.Encode(strings.Repeat("x", MaxTagLength)); !errors.Is(err, ErrTagTooLong) {
+		t.Fatalf("got %v; want %v", err, ErrTagTooLong)
+	}
+}